@@ -0,0 +1,185 @@
+// Package accesslog 为访问日志补充地理位置
+//
+// 从 nginx/Apache combined 日志或 JSON 日志中提取客户端 IP，按批去重后通过 Engine 并发查询，
+// 为每行追加国家、省、市、ISP、ASN，输出 JSON Lines 或 CSV
+//
+//	engine := geoip.New(geoip.Chinese)
+//	p, err := accesslog.Run(ctx, engine, os.Stdin, os.Stdout, accesslog.Options{
+//		Extractor:   accesslog.JSONField("client.ip"),
+//		MaxRequests: 1000, // 免费 API 的额度
+//		Progress:    func(p accesslog.Progress) { log.Println(p) },
+//	})
+package accesslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"time"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+// ErrRequestLimit 本次处理的 provider 请求数达到 Options.MaxRequests
+var ErrRequestLimit = errors.New("provider request limit reached")
+
+// maxLineSize 单行日志的最大字节数
+const maxLineSize = 1 << 20
+
+// Output 输出格式
+type Output int
+
+const (
+	JSONL Output = iota // JSON 日志追加 geo_* 字段，其它日志输出 {"line": ..., "ip": ..., "geo_*": ...}
+	CSV                 // ip,country_code,country,region,city,isp,asn,error,line
+)
+
+// Options 处理参数
+type Options struct {
+	Extractor Extractor // 提取客户端 IP，默认 Combined
+	Output    Output
+	Workers   int // 并发查询数，默认 8
+	ChunkSize int // 每批读取的行数，批内的 IP 去重后查询，默认 512
+	// MaxRequests 本次处理允许的 provider 请求总数，0 为不限制
+	// 每批处理前检查，达到后返回 ErrRequestLimit，最多超出一批的不同 IP 数
+	MaxRequests uint64
+	// Progress 每批输出后调用
+	Progress func(Progress)
+}
+
+// Progress 处理进度
+type Progress struct {
+	Lines     int                   // 已输出的行数
+	Matched   int                   // 提取到 IP 的行数
+	Lookups   int                   // 查询的 IP 数，批内去重，可能命中缓存
+	Failures  int                   // 查询失败的 IP 数，不含内网地址
+	Elapsed   time.Duration         // 已用时间
+	Providers []geoip.ProviderStats // 本次处理产生的 provider 请求
+}
+
+// Requests 本次处理产生的 provider 请求总数
+func (p Progress) Requests() uint64 {
+	var n uint64
+	for _, s := range p.Providers {
+		n += s.Requests
+	}
+	return n
+}
+
+func (p Progress) String() string {
+	return fmt.Sprintf("lines=%d matched=%d lookups=%d failures=%d requests=%d elapsed=%s",
+		p.Lines, p.Matched, p.Lookups, p.Failures, p.Requests(), p.Elapsed.Round(time.Millisecond))
+}
+
+// Run 逐批读取 r 中的日志，补充地理位置后按原顺序写入 w
+// 没有提取到 IP 的行原样输出，不包含地理位置
+func Run(ctx context.Context, engine *geoip.Engine, r io.Reader, w io.Writer, opt Options) (Progress, error) {
+	if opt.Extractor == nil {
+		opt.Extractor = Combined()
+	}
+	if opt.Workers <= 0 {
+		opt.Workers = 8
+	}
+	if opt.ChunkSize <= 0 {
+		opt.ChunkSize = 512
+	}
+	out := newWriter(w, opt.Output)
+
+	start := time.Now()
+	base := engine.Stats().Providers
+	var p Progress
+	update := func() {
+		p.Elapsed = time.Since(start)
+		p.Providers = providerDelta(base, engine.Stats().Providers)
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	lines := make([][]byte, 0, opt.ChunkSize)
+	for {
+		lines = lines[:0]
+		for len(lines) < opt.ChunkSize && sc.Scan() {
+			lines = append(lines, bytes.Clone(sc.Bytes()))
+		}
+		if len(lines) == 0 {
+			break
+		}
+		if opt.MaxRequests > 0 && p.Requests() >= opt.MaxRequests {
+			return p, ErrRequestLimit
+		}
+		if err := ctx.Err(); err != nil {
+			return p, err
+		}
+		if err := process(ctx, engine, out, lines, opt, &p); err != nil {
+			return p, err
+		}
+		update()
+		if opt.Progress != nil {
+			opt.Progress(p)
+		}
+	}
+	update()
+	return p, sc.Err()
+}
+
+// process 处理一批日志
+func process(ctx context.Context, engine *geoip.Engine, out writer, lines [][]byte, opt Options, p *Progress) error {
+	addrs := make([]netip.Addr, len(lines))
+	index := make(map[netip.Addr]int)
+	var ips []string
+	for i, line := range lines {
+		addr, ok := opt.Extractor.Extract(line)
+		if !ok {
+			continue
+		}
+		addrs[i] = addr
+		p.Matched++
+		if _, ok := index[addr]; !ok {
+			index[addr] = len(ips)
+			ips = append(ips, addr.String())
+		}
+	}
+
+	results := engine.LookupBatch(ctx, ips, opt.Workers)
+	p.Lookups += len(results)
+	for i := range results {
+		// 内网地址没有地理位置，不视为失败
+		if geoip.IsErrPrivateIP(results[i].Err) {
+			results[i].Err = nil
+		} else if results[i].Err != nil {
+			p.Failures++
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for i, line := range lines {
+		var res geoip.BatchResult
+		if addrs[i].IsValid() {
+			res = results[index[addrs[i]]]
+		}
+		if err := out.write(line, res); err != nil {
+			return err
+		}
+		p.Lines++
+	}
+	return out.flush()
+}
+
+// providerDelta 返回 cur 相对 base 增加的请求数
+func providerDelta(base, cur []geoip.ProviderStats) []geoip.ProviderStats {
+	out := make([]geoip.ProviderStats, len(cur))
+	for i, s := range cur {
+		out[i] = s
+		if i < len(base) {
+			out[i].Requests -= base[i].Requests
+			out[i].Failures -= base[i].Failures
+		}
+	}
+	return out
+}
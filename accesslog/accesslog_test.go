@@ -0,0 +1,131 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+func newTestEngine(t *testing.T) *geoip.Engine {
+	db, err := geoip.LoadCSV(strings.NewReader("183.95.0.0,183.95.255.255,中国,湖北省,荆门市,联通\n"), geoip.CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return geoip.New(geoip.Chinese, geoip.WithHandlers(db))
+}
+
+func TestExtractor(t *testing.T) {
+	re, err := Regexp(`client=(\S+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		x    Extractor
+		line string
+		want string
+	}{
+		{Combined(), `183.95.1.1 - - [10/Oct/2024:13:55:36 +0800] "GET / HTTP/1.1" 200 612 "-" "curl/8.0"`, "183.95.1.1"},
+		{Combined(), `[2001:db8::1]:443 - - [10/Oct/2024:13:55:36 +0800] "GET / HTTP/1.1" 200 612`, "2001:db8::1"},
+		{Combined(), `- - - [10/Oct/2024:13:55:36 +0800]`, ""},
+		{JSONField("client.ip"), `{"client":{"ip":"183.95.1.1:5678"}}`, "183.95.1.1"},
+		{JSONField("remote_addr"), `{"remote_addr":"183.95.1.1, 10.0.0.1"}`, "183.95.1.1"},
+		{JSONField("remote_addr"), `not json`, ""},
+		{re, `ts=1 client=::ffff:183.95.1.1 path=/`, "183.95.1.1"},
+	} {
+		addr, ok := tc.x.Extract([]byte(tc.line))
+		if got := addr.String(); (tc.want == "" && ok) || (tc.want != "" && got != tc.want) {
+			t.Fatalf("extract %q not match, got: %s %v", tc.line, got, ok)
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+	if _, err := Regexp(`client=\S+`); err == nil {
+		t.Fatal("expected error for regexp without group")
+	}
+}
+
+func TestRun(t *testing.T) {
+	engine := newTestEngine(t)
+	input := strings.Join([]string{
+		`{"remote_addr":"183.95.1.1","path":"/"}`,
+		`{"remote_addr":"192.168.1.1"}`,
+		`{"remote_addr":"183.95.1.1","path":"/a"}`,
+		`{"remote_addr":"8.8.8.8"}`,
+		`{}`,
+		`broken`,
+	}, "\n")
+
+	var buf bytes.Buffer
+	var calls int
+	p, err := Run(context.Background(), engine, strings.NewReader(input), &buf, Options{
+		Extractor: JSONField("remote_addr"),
+		ChunkSize: 4,
+		Progress:  func(Progress) { calls++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		`{"remote_addr":"183.95.1.1","path":"/","geo_country_code":"CN","geo_country":"中国","geo_region":"湖北省","geo_city":"荆门市","geo_isp":"联通"}`,
+		`{"remote_addr":"192.168.1.1"}`,
+		`{"remote_addr":"183.95.1.1","path":"/a","geo_country_code":"CN","geo_country":"中国","geo_region":"湖北省","geo_city":"荆门市","geo_isp":"联通"}`,
+		`{"remote_addr":"8.8.8.8","geo_error":"rangedb: 8.8.8.8 not found"}`,
+		`{}`,
+		`{"line":"broken"}`,
+	}, "\n") + "\n"
+	if buf.String() != want {
+		t.Fatalf("jsonl not match, got:\n%s", buf.String())
+	}
+	if p.Lines != 6 || p.Matched != 4 || p.Lookups != 3 || p.Failures != 1 || p.Requests() != 2 || calls != 2 {
+		t.Fatalf("progress not match, got: %+v %d", p, calls)
+	}
+
+	buf.Reset()
+	if _, err := Run(context.Background(), engine, strings.NewReader(`183.95.1.1 - - [10/Oct/2024:13:55:36 +0800] "GET / HTTP/1.1" 200 612`), &buf, Options{Output: CSV}); err != nil {
+		t.Fatal(err)
+	}
+	want = "ip,country_code,country,region,city,isp,asn,error,line\n" +
+		`183.95.1.1,CN,中国,湖北省,荆门市,联通,,,"183.95.1.1 - - [10/Oct/2024:13:55:36 +0800] ""GET / HTTP/1.1"" 200 612"` + "\n"
+	if buf.String() != want {
+		t.Fatalf("csv not match, got:\n%s", buf.String())
+	}
+}
+
+func TestRunRequestLimit(t *testing.T) {
+	engine := newTestEngine(t)
+	input := "183.95.1.1\n183.95.1.2\n183.95.1.3\n"
+	var buf bytes.Buffer
+	p, err := Run(context.Background(), engine, strings.NewReader(input), &buf, Options{ChunkSize: 2, MaxRequests: 2})
+	if !errors.Is(err, ErrRequestLimit) || p.Lines != 2 || strings.Count(buf.String(), "\n") != 2 {
+		t.Fatalf("request limit not match, got: %v %+v", err, p)
+	}
+}
+
+func TestMultiReader(t *testing.T) {
+	for _, wrap := range []func(io.Reader) io.Reader{
+		func(r io.Reader) io.Reader { return r },
+		iotest.OneByteReader,
+		iotest.DataErrReader,
+	} {
+		r := MultiReader(wrap(strings.NewReader("a\nb")), wrap(strings.NewReader("")), wrap(strings.NewReader("c\n")), wrap(strings.NewReader("d")))
+		data, err := io.ReadAll(r)
+		if err != nil || string(data) != "a\nb\nc\nd\n" {
+			t.Fatalf("data not match, got: %q %v", data, err)
+		}
+	}
+
+	// 最后一行不能与下一个文件的第一行合并
+	var out bytes.Buffer
+	p, err := Run(context.Background(), newTestEngine(t), MultiReader(strings.NewReader("183.95.1.1 - -"), strings.NewReader("183.95.1.2 - -\n")), &out, Options{})
+	if err != nil || p.Lines != 2 || p.Matched != 2 {
+		t.Fatalf("progress not match, got: %+v %v", p, err)
+	}
+}
@@ -0,0 +1,118 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// Extractor 从一行日志中提取客户端 IP，没有有效 IP 时返回 false
+type Extractor interface {
+	Extract(line []byte) (netip.Addr, bool)
+}
+
+// ExtractorFunc 将函数转换为 Extractor
+type ExtractorFunc func(line []byte) (netip.Addr, bool)
+
+// Extract implements Extractor.
+func (f ExtractorFunc) Extract(line []byte) (netip.Addr, bool) {
+	return f(line)
+}
+
+// Combined nginx/Apache 的 combined 与 common 格式，客户端 IP 为第一个字段
+//
+//	183.95.1.1 - - [10/Oct/2024:13:55:36 +0800] "GET / HTTP/1.1" 200 612 "-" "curl/8.0"
+func Combined() Extractor {
+	return ExtractorFunc(func(line []byte) (netip.Addr, bool) {
+		field, _, _ := bytes.Cut(bytes.TrimLeft(line, " \t"), []byte(" "))
+		return parseAddr(string(field))
+	})
+}
+
+// JSONField JSON 日志，path 为以 . 分隔的字段路径，如 remote_addr、client.ip
+func JSONField(path string) Extractor {
+	keys := strings.Split(path, ".")
+	return ExtractorFunc(func(line []byte) (netip.Addr, bool) {
+		var v any
+		if err := json.Unmarshal(line, &v); err != nil {
+			return netip.Addr{}, false
+		}
+		for _, k := range keys {
+			m, ok := v.(map[string]any)
+			if !ok {
+				return netip.Addr{}, false
+			}
+			v = m[k]
+		}
+		s, _ := v.(string)
+		return parseAddr(s)
+	})
+}
+
+// Regexp 按正则表达式提取，使用名为 ip 的分组，没有时使用第一个分组
+func Regexp(expr string) (Extractor, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	idx := re.SubexpIndex("ip")
+	if idx < 0 {
+		if re.NumSubexp() == 0 {
+			return nil, fmt.Errorf("regexp %q has no group", expr)
+		}
+		idx = 1
+	}
+	return ExtractorFunc(func(line []byte) (netip.Addr, bool) {
+		m := re.FindSubmatch(line)
+		if m == nil {
+			return netip.Addr{}, false
+		}
+		return parseAddr(string(m[idx]))
+	}), nil
+}
+
+// ParseFormat 根据名称创建 Extractor
+//
+//	combined、common    nginx/Apache 默认格式
+//	json:client.ip      JSON 日志的字段路径
+//	regex:client=(\S+)  正则表达式，参见 Regexp
+func ParseFormat(s string) (Extractor, error) {
+	kind, arg, _ := strings.Cut(s, ":")
+	switch strings.ToLower(kind) {
+	case "combined", "common", "":
+		return Combined(), nil
+	case "json":
+		if arg == "" {
+			arg = "remote_addr"
+		}
+		return JSONField(arg), nil
+	case "regex", "regexp":
+		return Regexp(arg)
+	}
+	return nil, fmt.Errorf("unknown log format %q", s)
+}
+
+// parseAddr 解析 IP、IP:port、[IPv6]:port，X-Forwarded-For 形式的列表取第一个
+func parseAddr(s string) (netip.Addr, bool) {
+	s, _, _ = strings.Cut(s, ",")
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return netip.Addr{}, false
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(s, "[]")); err == nil {
+		return addr.Unmap(), true
+	}
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,52 @@
+package accesslog
+
+import "io"
+
+// MultiReader 依次读取多个日志文件，与 io.MultiReader 不同，
+// 末尾没有换行的文件会补充换行，避免最后一行与下一个文件的第一行合并
+func MultiReader(readers ...io.Reader) io.Reader {
+	rs := make([]io.Reader, len(readers))
+	for i, r := range readers {
+		rs[i] = &newlineReader{r: r}
+	}
+	return io.MultiReader(rs...)
+}
+
+// newlineReader 在非空且不以换行结尾的内容之后补充换行
+type newlineReader struct {
+	r       io.Reader
+	last    byte
+	eof     bool
+	pending bool // 还需要输出补充的换行
+}
+
+func (l *newlineReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if l.eof {
+		if l.pending {
+			l.pending = false
+			p[0] = '\n'
+			return 1, io.EOF
+		}
+		return 0, io.EOF
+	}
+	n, err := l.r.Read(p)
+	if n > 0 {
+		l.last = p[n-1]
+	}
+	if err == io.EOF {
+		l.eof = true
+		l.pending = l.last != 0 && l.last != '\n'
+		if l.pending && n < len(p) {
+			p[n] = '\n'
+			n++
+			l.pending = false
+		}
+		if l.pending {
+			return n, nil
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,125 @@
+package accesslog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+type writer interface {
+	write(line []byte, res geoip.BatchResult) error
+	flush() error
+}
+
+func newWriter(w io.Writer, o Output) writer {
+	if o == CSV {
+		return &csvWriter{w: csv.NewWriter(w)}
+	}
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+// geoFields 追加到每行的地理位置
+type geoFields struct {
+	CountryCode string `json:"geo_country_code,omitempty"`
+	Country     string `json:"geo_country,omitempty"`
+	Region      string `json:"geo_region,omitempty"`
+	City        string `json:"geo_city,omitempty"`
+	ISP         string `json:"geo_isp,omitempty"`
+	ASN         int    `json:"geo_asn,omitempty"`
+	Error       string `json:"geo_error,omitempty"`
+}
+
+func newGeoFields(res geoip.BatchResult) geoFields {
+	if res.Err != nil {
+		return geoFields{Error: res.Err.Error()}
+	}
+	info := res.Info
+	if info == nil {
+		return geoFields{}
+	}
+	isp := info.ISP
+	if info.Carrier != nil {
+		isp = info.Carrier.Name
+	}
+	return geoFields{
+		CountryCode: info.CountryCode,
+		Country:     info.Country,
+		Region:      info.Region,
+		City:        info.City,
+		ISP:         isp,
+		ASN:         info.ASN,
+	}
+}
+
+type jsonWriter struct {
+	w *bufio.Writer
+}
+
+func (j *jsonWriter) write(line []byte, res geoip.BatchResult) error {
+	g := newGeoFields(res)
+	geo, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	// JSON 对象在末尾追加字段，保留原有字段的顺序
+	if obj := bytes.TrimSpace(line); len(obj) > 0 && obj[0] == '{' && json.Valid(obj) {
+		obj = bytes.TrimSpace(obj[:len(obj)-1])
+		geo = geo[1 : len(geo)-1]
+		j.w.Write(obj)
+		if len(geo) > 0 {
+			if obj[len(obj)-1] != '{' {
+				j.w.WriteByte(',')
+			}
+			j.w.Write(geo)
+		}
+		_, err := j.w.WriteString("}\n")
+		return err
+	}
+
+	v := struct {
+		Line string `json:"line"`
+		IP   string `json:"ip,omitempty"`
+		geoFields
+	}{Line: string(line), IP: res.IP, geoFields: g}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	j.w.Write(data)
+	return j.w.WriteByte('\n')
+}
+
+func (j *jsonWriter) flush() error {
+	return j.w.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) write(line []byte, res geoip.BatchResult) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write([]string{"ip", "country_code", "country", "region", "city", "isp", "asn", "error", "line"}); err != nil {
+			return err
+		}
+	}
+	g := newGeoFields(res)
+	var asn string
+	if g.ASN > 0 {
+		asn = strconv.Itoa(g.ASN)
+	}
+	return c.w.Write([]string{res.IP, g.CountryCode, g.Country, g.Region, g.City, g.ISP, asn, g.Error, string(line)})
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
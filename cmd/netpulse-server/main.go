@@ -0,0 +1,107 @@
+// netpulse-server 通过 HTTP 提供 geoip 查询服务，便于其它语言的服务共享同一个带缓存的查询服务
+//
+//	GET  /lookup/{ip}   查询指定 IP
+//	GET  /lookup        查询调用方的 IP
+//	POST /batch         批量查询，请求体为 JSON 数组或每行一个 IP
+//	GET  /myip          调用方的 IP
+//	GET  /healthz       健康检查
+//	GET  /stats         查询统计
+//
+// 默认输出 JSON，?format=text 或 Accept: text/plain 时输出纯文本
+// 参数可以通过命令行或环境变量配置，Engine 的配置参见 geoip.LoadConfig 与 geoip.ConfigFromEnv
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ixugo/netpulse/geoip"
+	"github.com/ixugo/netpulse/middleware"
+	_ "github.com/ixugo/netpulse/rdap" // 注册 rdap provider
+)
+
+func main() {
+	var (
+		addr     = flag.String("addr", envOr("NETPULSE_SERVER_ADDR", ":8080"), "listen address, env NETPULSE_SERVER_ADDR")
+		config   = flag.String("config", os.Getenv("NETPULSE_CONFIG"), "engine config file (json/yaml/toml), env NETPULSE_CONFIG; NETPULSE_* variables are used when empty")
+		proxies  = flag.String("trusted-proxies", os.Getenv("NETPULSE_TRUSTED_PROXIES"), "comma separated trusted proxy CIDRs, env NETPULSE_TRUSTED_PROXIES")
+		maxBatch = flag.Int("max-batch", envInt("NETPULSE_MAX_BATCH", 100), "max IPs per batch request, env NETPULSE_MAX_BATCH")
+	)
+	flag.Parse()
+
+	if err := run(*addr, *config, *proxies, *maxBatch); err != nil {
+		slog.Error("netpulse-server", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(addr, config, proxies string, maxBatch int) error {
+	engine, err := newEngine(config)
+	if err != nil {
+		return err
+	}
+	var trusted []string
+	for p := range strings.SplitSeq(proxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trusted = append(trusted, p)
+		}
+	}
+	prefixes, err := middleware.ParseTrustedProxies(trusted...)
+	if err != nil {
+		return err
+	}
+
+	srv := http.Server{
+		Addr:              addr,
+		Handler:           newServer(engine, prefixes, maxBatch).routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+
+	slog.Info("netpulse-server listening", "addr", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func newEngine(config string) (*geoip.Engine, error) {
+	if config == "" {
+		return geoip.NewFromEnv()
+	}
+	cfg, err := geoip.LoadConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return geoip.NewFromConfig(cfg)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
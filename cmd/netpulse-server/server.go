@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/ixugo/netpulse/geoip"
+	"github.com/ixugo/netpulse/middleware"
+)
+
+// maxBodySize 批量查询请求体的最大字节数
+const maxBodySize = 1 << 20
+
+type server struct {
+	engine   *geoip.Engine
+	trusted  []netip.Prefix
+	maxBatch int
+	started  time.Time
+}
+
+func newServer(engine *geoip.Engine, trusted []netip.Prefix, maxBatch int) *server {
+	return &server{engine: engine, trusted: trusted, maxBatch: maxBatch, started: time.Now()}
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /lookup/{ip}", s.lookup)
+	mux.HandleFunc("GET /lookup", s.lookupCaller)
+	mux.HandleFunc("POST /batch", s.batch)
+	mux.HandleFunc("GET /myip", s.myIP)
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /stats", s.stats)
+	return middleware.New(s.engine, middleware.WithTrustedProxies(s.trusted...), middleware.WithLazy())(mux)
+}
+
+func (s *server) lookup(w http.ResponseWriter, r *http.Request) {
+	ip := r.PathValue("ip")
+	info, err := s.engine.Lookup(r.Context(), ip)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeInfo(w, r, ip, info)
+}
+
+func (s *server) lookupCaller(w http.ResponseWriter, r *http.Request) {
+	info, err := middleware.Info(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	ip, _ := middleware.ClientIP(r.Context())
+	writeInfo(w, r, ip.String(), info)
+}
+
+// batchResult 批量查询中单个 IP 的结果
+type batchResult struct {
+	IP    string
+	Info  *geoip.Info `json:",omitempty"`
+	Error string      `json:",omitempty"`
+}
+
+func (s *server) batch(w http.ResponseWriter, r *http.Request) {
+	ips, err := readIPs(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		writeStatus(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if len(ips) > s.maxBatch {
+		writeStatus(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("too many ips: %d > %d", len(ips), s.maxBatch))
+		return
+	}
+
+	results := s.engine.LookupBatch(r.Context(), ips, 0)
+	if wantText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, res := range results {
+			if res.Err != nil {
+				fmt.Fprintf(w, "%s\terror: %s\n", res.IP, res.Err)
+				continue
+			}
+			fmt.Fprintln(w, infoLine(res.IP, res.Info))
+		}
+		return
+	}
+	out := make([]batchResult, len(results))
+	for i, res := range results {
+		out[i] = batchResult{IP: res.IP, Info: res.Info}
+		if res.Err != nil {
+			out[i].Error = res.Err.Error()
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// readIPs 读取 JSON 数组，或每行一个 IP 的纯文本
+func readIPs(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var ips []string
+		if err := json.Unmarshal(trimmed, &ips); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		return ips, nil
+	}
+	var ips []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			ips = append(ips, line)
+		}
+	}
+	return ips, sc.Err()
+}
+
+func (s *server) myIP(w http.ResponseWriter, r *http.Request) {
+	ip, ok := middleware.ClientIP(r.Context())
+	if !ok {
+		writeError(w, r, middleware.ErrNoClientIP)
+		return
+	}
+	if wantText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, ip)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"IP": ip.String()})
+}
+
+func (s *server) healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *server) stats(w http.ResponseWriter, r *http.Request) {
+	stats := struct {
+		geoip.Stats
+		Uptime string
+	}{s.engine.Stats(), time.Since(s.started).Round(time.Second).String()}
+	if wantText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "uptime\t%s\nlookups\t%d\ncache_hits\t%d\nfailures\t%d\n", stats.Uptime, stats.Lookups, stats.CacheHits, stats.Failures)
+		for _, p := range stats.Providers {
+			fmt.Fprintf(w, "provider\t%s\t%d\t%d\n", p.Name, p.Requests, p.Failures)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// wantText ?format=text，或未指定 format 且 Accept 为 text/plain
+func wantText(r *http.Request) bool {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return f == "text"
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/plain") && !strings.Contains(accept, "application/json")
+}
+
+// infoLine 纯文本输出，以制表符分隔 ip、国家代码、国家、省、市、运营商
+func infoLine(ip string, info *geoip.Info) string {
+	isp := info.ISP
+	if info.Carrier != nil {
+		isp = info.Carrier.Name
+	}
+	return strings.Join([]string{ip, info.CountryCode, info.Country, info.Region, info.City, isp}, "\t")
+}
+
+func writeInfo(w http.ResponseWriter, r *http.Request, ip string, info *geoip.Info) {
+	if wantText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, infoLine(ip, info))
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// writeError 按错误类型返回状态码
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadGateway
+	switch {
+	case geoip.IsErrPrivateIP(err), errors.Is(err, geoip.ErrInvalidIP), errors.Is(err, middleware.ErrNoClientIP):
+		status = http.StatusBadRequest
+	case errors.Is(err, geoip.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, geoip.ErrNoProviders):
+		status = http.StatusServiceUnavailable
+	}
+	writeStatus(w, r, status, err)
+}
+
+func writeStatus(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if wantText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+	writeJSON(w, status, map[string]string{"Error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
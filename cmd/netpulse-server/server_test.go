@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ixugo/netpulse/geoip"
+	"github.com/ixugo/netpulse/middleware"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	db, err := geoip.LoadCSV(strings.NewReader("183.95.0.0,183.95.255.255,中国,湖北省,荆门市,联通\n"), geoip.CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted, _ := middleware.ParseTrustedProxies("127.0.0.1", "::1")
+	ts := httptest.NewServer(newServer(geoip.New(geoip.Chinese, geoip.WithHandlers(db)), trusted, 2).routes())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, req *http.Request) (int, string) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer(t *testing.T) {
+	ts := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/lookup/183.95.1.1", nil)
+	status, body := get(t, req)
+	var info geoip.Info
+	if err := json.Unmarshal([]byte(body), &info); status != http.StatusOK || err != nil || info.City != "荆门市" || info.Carrier.ID != "china-unicom" {
+		t.Fatalf("lookup not match, got: %d %s", status, body)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/lookup?format=text", nil)
+	req.Header.Set("X-Forwarded-For", "183.95.2.2")
+	if status, body = get(t, req); status != http.StatusOK || body != "183.95.2.2\tCN\t中国\t湖北省\t荆门市\t联通\n" {
+		t.Fatalf("caller lookup not match, got: %d %q", status, body)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/myip", nil)
+	req.Header.Set("Accept", "text/plain")
+	req.Header.Set("X-Real-IP", "203.0.113.9")
+	if _, body = get(t, req); body != "203.0.113.9\n" {
+		t.Fatalf("myip not match, got: %q", body)
+	}
+
+	for path, want := range map[string]int{
+		"/lookup/nope":     http.StatusBadRequest,
+		"/lookup/10.0.0.1": http.StatusBadRequest,
+		"/lookup/8.8.8.8":  http.StatusNotFound,
+	} {
+		req, _ = http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if status, body = get(t, req); status != want || !strings.Contains(body, "Error") {
+			t.Fatalf("%s not match, got: %d %s", path, status, body)
+		}
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/healthz", nil)
+	if status, body = get(t, req); status != http.StatusOK || body != "ok\n" {
+		t.Fatalf("healthz not match, got: %d %s", status, body)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/stats", nil)
+	var stats geoip.Stats
+	if _, body = get(t, req); json.Unmarshal([]byte(body), &stats) != nil || stats.Lookups != 3 || len(stats.Providers) != 1 {
+		t.Fatalf("stats not match, got: %s", body)
+	}
+}
+
+func TestServerNoProviders(t *testing.T) {
+	// ja 没有默认 provider
+	ts := httptest.NewServer(newServer(geoip.New("ja"), nil, 2).routes())
+	defer ts.Close()
+
+	for _, path := range []string{"/lookup/8.8.8.8?format=text", "/lookup/8.8.8.8"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if status, body := get(t, req); status != http.StatusServiceUnavailable || !strings.Contains(body, "no providers") {
+			t.Fatalf("%s not match, got: %d %q", path, status, body)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/batch?format=text", strings.NewReader("8.8.8.8\n"))
+	if status, body := get(t, req); status != http.StatusOK || body != "8.8.8.8\terror: no providers\n" {
+		t.Fatalf("batch not match, got: %d %q", status, body)
+	}
+}
+
+func TestServerBatch(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Post(ts.URL+"/batch", "application/json", strings.NewReader(`["183.95.1.1", "8.8.8.8"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []batchResult
+	err = json.NewDecoder(resp.Body).Decode(&results)
+	resp.Body.Close()
+	if err != nil || len(results) != 2 || results[0].Info == nil || results[1].Error == "" {
+		t.Fatalf("batch not match, got: %+v %v", results, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/batch?format=text", strings.NewReader("183.95.1.1\n\n10.0.0.1\n"))
+	if _, body := get(t, req); body != "183.95.1.1\tCN\t中国\t湖北省\t荆门市\t联通\n10.0.0.1\terror: private ip\n" {
+		t.Fatalf("text batch not match, got: %q", body)
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+"/batch", strings.NewReader("1.1.1.1\n2.2.2.2\n3.3.3.3\n"))
+	if status, _ := get(t, req); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("batch limit not match, got: %d", status)
+	}
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+"/batch", strings.NewReader("[1,"))
+	if status, _ := get(t, req); status != http.StatusBadRequest {
+		t.Fatalf("invalid batch not match, got: %d", status)
+	}
+}
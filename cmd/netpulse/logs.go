@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/ixugo/netpulse/accesslog"
+)
+
+// cmdLogs 为访问日志补充地理位置，进度与 provider 请求数输出到标准错误
+func cmdLogs(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: netpulse logs [flags] [file...] (stdin when no file is given)")
+		fs.PrintDefaults()
+	}
+	var ef engineFlags
+	ef.register(fs)
+	format := fs.String("format", "combined", "log format: combined, common, json:<field.path>, regex:<expr with (?P<ip>...)>")
+	csv := fs.Bool("csv", false, "print CSV instead of JSON Lines")
+	concurrency := fs.Int("c", 8, "concurrent lookups")
+	maxRequests := fs.Uint64("max-requests", 0, "stop after this many provider requests, 0 for no limit")
+	quiet := fs.Bool("q", false, "do not print progress")
+	files, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	extractor, err := accesslog.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	engine, err := ef.engine()
+	if err != nil {
+		return err
+	}
+
+	opt := accesslog.Options{
+		Extractor:   extractor,
+		Workers:     *concurrency,
+		MaxRequests: *maxRequests,
+	}
+	if *csv {
+		opt.Output = accesslog.CSV
+	}
+	if !*quiet {
+		opt.Progress = func(p accesslog.Progress) {
+			fmt.Fprintln(os.Stderr, p)
+		}
+	}
+
+	readers := []io.Reader{stdin}
+	if len(files) > 0 {
+		readers = readers[:0]
+		for _, name := range files {
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			readers = append(readers, f)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	p, err := accesslog.Run(ctx, engine, accesslog.MultiReader(readers...), stdout, opt)
+	if !*quiet {
+		for _, s := range p.Providers {
+			if s.Requests > 0 {
+				fmt.Fprintf(os.Stderr, "provider %s: requests=%d failures=%d\n", s.Name, s.Requests, s.Failures)
+			}
+		}
+	}
+	return err
+}
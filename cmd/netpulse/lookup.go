@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+// enrichChunk enrich 每次批量查询的 IP 数量，查询完一批即输出
+const enrichChunk = 256
+
+// formatFlags 输出格式，默认为 table
+type formatFlags struct {
+	json, table, csv bool
+}
+
+func (f *formatFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&f.json, "json", false, "print one JSON object per line")
+	fs.BoolVar(&f.table, "table", false, "print an aligned table (default)")
+	fs.BoolVar(&f.csv, "csv", false, "print CSV with a header line")
+}
+
+func (f *formatFlags) writer(w io.Writer) (resultWriter, error) {
+	n := 0
+	for _, v := range []bool{f.json, f.table, f.csv} {
+		if v {
+			n++
+		}
+	}
+	if n > 1 {
+		return nil, errors.New("only one of -json, -table, -csv may be given")
+	}
+	switch {
+	case f.json:
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case f.csv:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	}
+	return &tableWriter{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}, nil
+}
+
+// resultWriter 按格式输出查询结果，Flush 之后才保证全部写出
+type resultWriter interface {
+	Write(geoip.BatchResult) error
+	Flush() error
+}
+
+// isp 优先使用归一化的运营商名称
+func isp(info *geoip.Info) string {
+	if info.Carrier != nil {
+		return info.Carrier.Name
+	}
+	return info.ISP
+}
+
+func asn(info *geoip.Info) string {
+	if info.ASN <= 0 {
+		return ""
+	}
+	return "AS" + strconv.Itoa(info.ASN)
+}
+
+type tableWriter struct {
+	w      *tabwriter.Writer
+	header bool
+}
+
+func (t *tableWriter) Write(r geoip.BatchResult) error {
+	if !t.header {
+		t.header = true
+		fmt.Fprintln(t.w, "IP\tCC\tCOUNTRY\tREGION\tCITY\tISP\tASN")
+	}
+	if r.Err != nil {
+		_, err := fmt.Fprintf(t.w, "%s\terror: %s\n", r.IP, r.Err)
+		return err
+	}
+	i := r.Info
+	_, err := fmt.Fprintln(t.w, strings.Join([]string{r.IP, i.CountryCode, i.Country, i.Region, i.City, isp(i), asn(i)}, "\t"))
+	return err
+}
+
+func (t *tableWriter) Flush() error {
+	return t.w.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) Write(r geoip.BatchResult) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write([]string{"ip", "country_code", "country", "region", "city", "isp", "carrier", "asn", "latitude", "longitude", "error"}); err != nil {
+			return err
+		}
+	}
+	if r.Err != nil {
+		return c.w.Write([]string{r.IP, "", "", "", "", "", "", "", "", "", r.Err.Error()})
+	}
+	i := r.Info
+	var carrier, lat, lon string
+	if i.Carrier != nil {
+		carrier = i.Carrier.ID
+	}
+	if i.HasLocation() {
+		lat = strconv.FormatFloat(i.Latitude, 'f', -1, 64)
+		lon = strconv.FormatFloat(i.Longitude, 'f', -1, 64)
+	}
+	return c.w.Write([]string{r.IP, i.CountryCode, i.Country, i.Region, i.City, i.ISP, carrier, asn(i), lat, lon, ""})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter 输出 JSON Lines，字段与 netpulse-server 的批量查询结果一致
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonWriter) Write(r geoip.BatchResult) error {
+	v := struct {
+		IP    string
+		Info  *geoip.Info `json:",omitempty"`
+		Error string      `json:",omitempty"`
+	}{IP: r.IP, Info: r.Info}
+	if r.Err != nil {
+		v.Error = r.Err.Error()
+	}
+	return j.enc.Encode(v)
+}
+
+func (j *jsonWriter) Flush() error {
+	return nil
+}
+
+func cmdLookup(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("lookup", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: netpulse lookup [flags] <ip>...")
+		fs.PrintDefaults()
+	}
+	var ef engineFlags
+	var ff formatFlags
+	ef.register(fs)
+	ff.register(fs)
+	concurrency := fs.Int("c", 8, "concurrent lookups")
+	ips, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(ips) == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	w, err := ff.writer(stdout)
+	if err != nil {
+		return err
+	}
+	engine, err := ef.engine()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var failed bool
+	for _, r := range engine.LookupBatch(ctx, ips, *concurrency) {
+		failed = failed || r.Err != nil
+		if err := w.Write(r); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	// 错误已经在结果中输出
+	if failed {
+		return errSilent
+	}
+	return nil
+}
+
+// cmdEnrich 从标准输入读取 IP，每行取第一个字段，空行与 # 开头的行被忽略
+// 单个 IP 查询失败时在结果中输出错误，不影响退出码
+func cmdEnrich(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("enrich", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: netpulse enrich [flags] < ips.txt")
+		fs.PrintDefaults()
+	}
+	var ef engineFlags
+	var ff formatFlags
+	ef.register(fs)
+	ff.register(fs)
+	concurrency := fs.Int("c", 8, "concurrent lookups")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	w, err := ff.writer(stdout)
+	if err != nil {
+		return err
+	}
+	engine, err := ef.engine()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return enrich(ctx, engine, stdin, w, *concurrency)
+}
+
+func enrich(ctx context.Context, engine *geoip.Engine, r io.Reader, w resultWriter, concurrency int) error {
+	flush := func(ips []string) error {
+		for _, r := range engine.LookupBatch(ctx, ips, concurrency) {
+			if err := w.Write(r); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}
+
+	ips := make([]string, 0, enrichChunk)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.FieldsFunc(sc.Text(), func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		})
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		ips = append(ips, fields[0])
+		if len(ips) == enrichChunk {
+			if err := flush(ips); err != nil {
+				return err
+			}
+			ips = ips[:0]
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return flush(ips)
+}
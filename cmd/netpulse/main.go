@@ -0,0 +1,170 @@
+// netpulse 命令行工具，用于查询本机 IP 与 IP 的地理位置
+//
+//	netpulse myip [-6] [-internal]
+//	netpulse lookup [-provider ipapi,ipwho] [-json|-table|-csv] <ip>...
+//	netpulse enrich [-json|-table|-csv] < ips.txt
+//	netpulse logs [-format combined|json:remote_addr] [-csv] [-max-requests 1000] access.log
+//	netpulse providers [-check]
+//
+// 标志可以写在参数之后，-x 与 --x 等价
+// Engine 默认通过 NETPULSE_* 环境变量配置，参见 geoip.ConfigFromEnv，也可以通过 -config 指定配置文件
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ixugo/netpulse/geoip"
+	"github.com/ixugo/netpulse/ip"
+	_ "github.com/ixugo/netpulse/rdap" // 注册 rdap provider
+)
+
+const usage = `usage: netpulse <command> [flags] [args]
+
+commands:
+  myip       print the external (or internal) IP of this host
+  lookup     look up the location of the given IPs
+  enrich     look up IPs read from stdin, one per line
+  logs       add geolocation to nginx/Apache or JSON access logs
+  providers  list registered providers, -check to run a test lookup
+
+run "netpulse <command> -h" for the flags of a command
+`
+
+// errSilent 错误信息已经输出，只需要设置退出码
+var errSilent = errors.New("silent")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if !errors.Is(err, errSilent) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "netpulse:", err)
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "myip":
+		return cmdMyIP(args, stdout)
+	case "lookup":
+		return cmdLookup(args, stdout)
+	case "enrich":
+		return cmdEnrich(args, stdin, stdout)
+	case "logs":
+		return cmdLogs(args, stdin, stdout)
+	case "providers":
+		return cmdProviders(args, stdout)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	fmt.Fprint(os.Stderr, usage)
+	return fmt.Errorf("unknown command %q", cmd)
+}
+
+// parseFlags 解析标志，允许标志出现在位置参数之后，返回位置参数
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// -- 之后均为位置参数
+		if parsed := args[:len(args)-len(rest)]; len(parsed) > 0 && parsed[len(parsed)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func cmdMyIP(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("myip", flag.ContinueOnError)
+	v6 := fs.Bool("6", false, "print the external IPv6 address")
+	internal := fs.Bool("internal", false, "print the internal address used for outbound traffic")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var (
+		addr string
+		err  error
+	)
+	switch {
+	case *internal:
+		addr = ip.InternalIP()
+		if addr == "" {
+			err = errors.New("no internal ip")
+		}
+	case *v6:
+		addr, err = ip.ExternalIPv6()
+	default:
+		addr, err = ip.ExternalIP()
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, addr)
+	return err
+}
+
+// engineFlags lookup 与 enrich 共用的 Engine 参数
+type engineFlags struct {
+	config    string
+	language  string
+	providers string
+}
+
+func (f *engineFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.config, "config", os.Getenv("NETPULSE_CONFIG"), "engine config file (json/yaml/toml), env NETPULSE_CONFIG; NETPULSE_* variables are used when empty")
+	fs.StringVar(&f.language, "lang", "", "result language, e.g. zh-CN, en")
+	fs.StringVar(&f.providers, "provider", "", "comma separated providers to use in order, see netpulse providers")
+}
+
+func (f *engineFlags) engine() (*geoip.Engine, error) {
+	cfg := geoip.ConfigFromEnv()
+	if f.config != "" {
+		var err error
+		if cfg, err = geoip.LoadConfig(f.config); err != nil {
+			return nil, err
+		}
+	}
+	if f.language != "" {
+		cfg.Language = geoip.Language(f.language)
+	}
+	if f.providers != "" {
+		// 保留配置文件中同名 provider 的凭据
+		configured := make(map[string]geoip.ProviderConfig, len(cfg.Providers))
+		for _, pc := range cfg.Providers {
+			configured[pc.Name] = pc
+		}
+		cfg.Providers = nil
+		for name := range strings.SplitSeq(f.providers, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				pc, ok := configured[name]
+				if !ok {
+					pc = geoip.ProviderConfig{Name: name}
+				}
+				cfg.Providers = append(cfg.Providers, pc)
+			}
+		}
+	}
+	return geoip.NewFromConfig(cfg)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+func TestParseFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	j := fs.Bool("json", false, "")
+	p := fs.String("provider", "", "")
+	args, err := parseFlags(fs, []string{"1.1.1.1", "--json", "8.8.8.8", "-provider", "ipapi", "--", "-9.9.9.9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !*j || *p != "ipapi" || !slices.Equal(args, []string{"1.1.1.1", "8.8.8.8", "-9.9.9.9"}) {
+		t.Fatalf("parse flags not match, got: %v %v %q", args, *j, *p)
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	db, err := geoip.LoadCSV(strings.NewReader("183.95.0.0,183.95.255.255,中国,湖北省,荆门市,联通\n"), geoip.CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := geoip.New(geoip.Chinese, geoip.WithHandlers(db))
+	input := "# comment\n183.95.1.1 GET /index.html\n\n192.168.1.1,foo\n"
+
+	for _, tc := range []struct {
+		ff   formatFlags
+		want string
+	}{
+		{formatFlags{}, "IP           CC  COUNTRY  REGION  CITY  ISP  ASN\n183.95.1.1   CN  中国       湖北省     荆门市   联通   \n192.168.1.1  error: private ip\n"},
+		{formatFlags{csv: true}, "ip,country_code,country,region,city,isp,carrier,asn,latitude,longitude,error\n183.95.1.1,CN,中国,湖北省,荆门市,联通,china-unicom,,,,\n192.168.1.1,,,,,,,,,,private ip\n"},
+	} {
+		var buf bytes.Buffer
+		w, err := tc.ff.writer(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := enrich(context.Background(), engine, strings.NewReader(input), w, 2); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tc.want {
+			t.Fatalf("enrich not match, got: %q", buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	w, _ := (&formatFlags{json: true}).writer(&buf)
+	if err := enrich(context.Background(), engine, strings.NewReader("183.95.1.1\n"), w, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"IP":"183.95.1.1","Info":{`) || strings.Contains(buf.String(), "Error") {
+		t.Fatalf("json not match, got: %s", buf.String())
+	}
+
+	if _, err := (&formatFlags{json: true, csv: true}).writer(&buf); err == nil {
+		t.Fatal("expected error for multiple formats")
+	}
+}
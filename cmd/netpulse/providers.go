@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+// providerStatus 根据环境变量判断 provider 是否可用，返回凭据与缺少的环境变量
+func providerStatus(p geoip.Provider) (geoip.Credentials, []string) {
+	creds := make(geoip.Credentials, len(p.Credentials))
+	var missing []string
+	for _, c := range p.Credentials {
+		env := geoip.EnvName(p.Name, c.Name)
+		if v := os.Getenv(env); v != "" {
+			creds[c.Name] = v
+		} else if !c.Optional {
+			missing = append(missing, env)
+		}
+	}
+	return creds, missing
+}
+
+// checkResult provider 健康检查的结果
+type checkResult struct {
+	elapsed time.Duration
+	info    *geoip.Info
+	err     error
+}
+
+func (c checkResult) String() string {
+	if c.err != nil {
+		return "error: " + c.err.Error()
+	}
+	return fmt.Sprintf("ok %s %s", c.elapsed.Round(time.Millisecond), strings.Join([]string{c.info.CountryCode, c.info.Region, c.info.City}, " "))
+}
+
+func checkProvider(ctx context.Context, p geoip.Provider, creds geoip.Credentials, ip string, timeout time.Duration) checkResult {
+	iper, err := geoip.NewProvider(p.Name, creds)
+	if err != nil {
+		return checkResult{err: err}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	info, err := iper.Lookup(ctx, ip)
+	return checkResult{elapsed: time.Since(start), info: info, err: err}
+}
+
+func cmdProviders(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("providers", flag.ContinueOnError)
+	check := fs.Bool("check", false, "look up -ip with every usable provider")
+	ip := fs.String("ip", "8.8.8.8", "IP used by -check")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of each check")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	providers := geoip.Providers()
+	status := make([]string, len(providers))
+	results := make([]checkResult, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		creds, missing := providerStatus(p)
+		if len(missing) > 0 {
+			status[i] = "missing " + strings.Join(missing, ",")
+			continue
+		}
+		status[i] = "ready"
+		if !*check {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkProvider(context.Background(), p, creds, *ip, *timeout)
+		}()
+	}
+	wg.Wait()
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	header := "NAME\tLANGUAGES\tSTATUS\tDESCRIPTION"
+	if *check {
+		header += "\tCHECK"
+	}
+	fmt.Fprintln(tw, header)
+	var failed bool
+	for i, p := range providers {
+		langs := make([]string, len(p.Languages))
+		for j, l := range p.Languages {
+			langs[j] = string(l)
+		}
+		line := strings.Join([]string{p.Name, strings.Join(langs, ","), status[i], p.Description}, "\t")
+		if *check && status[i] == "ready" {
+			line += "\t" + results[i].String()
+			failed = failed || results[i].err != nil
+		}
+		fmt.Fprintln(tw, line)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed {
+		return errSilent
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package geoip
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// cnAdcodesCSV GB/T 2260 地级行政区，包含省直辖的县级行政区
+// code,name,en,aliases(| 分隔)，省级行政区见 cn_provinces.csv
+//
+//go:embed data/cn_adcodes.csv
+var cnAdcodesCSV string
+
+// cnDistrictsCSV GB/T 2260 县级行政区，包括市辖区、县、县级市、旗等，数据截至 2021 年民政部发布的行政区划代码
+// code,name,en，en 为去掉 区、县、自治县 等后缀的汉语拼音
+//
+//go:embed data/cn_districts.csv
+var cnDistrictsCSV string
+
+// municipalities 直辖市的省级代码前缀
+var municipalities = map[string]bool{"11": true, "12": true, "31": true, "50": true}
+
+// splitAdcode 根据 6 位行政区划代码返回省级与地级代码
+// 例如 420802 返回 420000, 420800；直辖市的地级代码与省级代码相同
+// 省直辖的县级行政区(如 429004 仙桃市)的地级代码为其自身
+func splitAdcode(adcode string) (region, city string) {
+	if !isAdcode(adcode) || adcode == "000000" {
+		return "", ""
+	}
+	region = adcode[:2] + "0000"
+	switch {
+	case municipalities[adcode[:2]] || adcode[2:4] == "00":
+		return region, region
+	case adcode[2:4] == "90":
+		return region, adcode
+	}
+	return region, adcode[:4] + "00"
+}
+
+// isAdcode 是否为 6 位数字
+func isAdcode(s string) bool {
+	if len(s) != 6 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Division 行政区划
+type Division struct {
+	Code   string // 6 位行政区划代码，如 420802
+	Name   string // 中文名称，如 东宝区
+	NameEn string // 拼音，LoadDivisions 未提供时可能为空
+}
+
+// name 中文返回中文名称，其它语言返回拼音，没有拼音时返回中文名称
+func (d *Division) name(lang Language) string {
+	if baseLanguage(lang) == "zh" || d.NameEn == "" {
+		return d.Name
+	}
+	return d.NameEn
+}
+
+// divisionTable 行政区划表，内置省级、地级与县级，可以通过 LoadDivisions 追加或覆盖
+type divisionTable struct {
+	mu     sync.RWMutex
+	byCode map[string]*Division
+	byName map[string]*Division // 省级代码前两位|地级名称，如 42|wuhan
+}
+
+var divisions = sync.OnceValue(func() *divisionTable {
+	t := divisionTable{
+		byCode: make(map[string]*Division),
+		byName: make(map[string]*Division),
+	}
+	for _, p := range names().provinceByAdcode {
+		t.byCode[p.adcode] = &Division{Code: p.adcode, Name: p.zh, NameEn: p.en}
+	}
+	for _, row := range readEmbeddedCSV(cnAdcodesCSV)[1:] {
+		var aliases []string
+		if len(row) > 3 && row[3] != "" {
+			aliases = strings.Split(row[3], "|")
+		}
+		t.add(&Division{Code: row[0], Name: row[1], NameEn: row[2]}, aliases...)
+	}
+	for _, row := range readEmbeddedCSV(cnDistrictsCSV)[1:] {
+		t.add(&Division{Code: row[0], Name: row[1], NameEn: row[2]})
+	}
+	return &t
+})
+
+// divisionSuffixes 地级名称常见的后缀，去掉后作为简称
+var divisionSuffixes = []string{"市", "地区", "盟", "县", "林区"}
+
+// divisionSuffixesEn 英文地级名称常见的后缀
+var divisionSuffixesEn = []string{" city", " shi", " prefecture", " league", " county"}
+
+func (t *divisionTable) add(d *Division, aliases ...string) {
+	t.byCode[d.Code] = d
+	// 只有地级行政区按名称索引
+	if _, city := splitAdcode(d.Code); city != d.Code || d.Code[2:] == "0000" {
+		return
+	}
+	keys := append([]string{d.Name, d.NameEn}, aliases...)
+	for _, suffix := range divisionSuffixes {
+		if s, ok := strings.CutSuffix(d.Name, suffix); ok && len([]rune(s)) >= 2 {
+			keys = append(keys, s)
+			break
+		}
+	}
+	for _, k := range keys {
+		if k = normalizeName(k); k != "" {
+			t.byName[d.Code[:2]+"|"+k] = d
+		}
+	}
+}
+
+// city 在省级行政区内按中文或拼音名称查找地级行政区
+func (t *divisionTable) city(province, name string) *Division {
+	key := normalizeName(name)
+	if key == "" {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if d := t.byName[province+"|"+key]; d != nil {
+		return d
+	}
+	for _, suffix := range divisionSuffixesEn {
+		if s, ok := strings.CutSuffix(key, suffix); ok {
+			return t.byName[province+"|"+s]
+		}
+	}
+	return nil
+}
+
+// LookupDivision 返回行政区划代码对应的行政区，内置表包含省级、地级与县级
+func LookupDivision(adcode string) (Division, bool) {
+	t := divisions()
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if d := t.byCode[adcode]; d != nil {
+		return *d, true
+	}
+	return Division{}, false
+}
+
+// DivisionHierarchy 返回行政区划代码从省级到区县的各级行政区
+// 例如 420802 返回 湖北省、荆门市、东宝区；直辖市不重复返回地级，未知的层级会被跳过
+func DivisionHierarchy(adcode string) []Division {
+	region, city := splitAdcode(adcode)
+	if region == "" {
+		return nil
+	}
+	codes := []string{region}
+	if city != region {
+		codes = append(codes, city)
+	}
+	if adcode != city {
+		codes = append(codes, adcode)
+	}
+	out := make([]Division, 0, len(codes))
+	for _, code := range codes {
+		if d, ok := LookupDivision(code); ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// FindAdcode 根据中文或拼音的省份、城市名称返回行政区划代码，省份也可以是行政区划代码
+// 例如 ("Hubei", "Wuhan")、("湖北省", "武汉") 均返回 420000, 420100；直辖市的城市代码与省级代码相同
+// 无法识别省份时返回空字符串，无法识别城市时 cityCode 为空
+func FindAdcode(region, city string) (regionCode, cityCode string) {
+	t := names()
+	var p *provinceEntry
+	if isAdcode(region) {
+		p = t.provinceByAdcode[region[:2]]
+	} else {
+		p = t.province(region)
+	}
+	if p == nil {
+		return "", ""
+	}
+	if municipalities[p.adcode[:2]] {
+		return p.adcode, p.adcode
+	}
+	if d := divisions().city(p.adcode[:2], city); d != nil {
+		return p.adcode, d.Code
+	}
+	return p.adcode, ""
+}
+
+// LoadDivisions 追加行政区划，用于加载内置表之后新设或调整的区县，相同代码会覆盖内置的记录
+// 每行为 code,name[,en] 或以空白分隔的 code name，首行不能是表头，空行会被忽略
+func LoadDivisions(r io.Reader) error {
+	var list []*Division
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var fields []string
+		if strings.Contains(text, ",") {
+			fields = strings.Split(text, ",")
+		} else {
+			fields = strings.Fields(text)
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if len(fields) < 2 || !isAdcode(fields[0]) || fields[1] == "" {
+			return fmt.Errorf("divisions line %d: expected code,name", line)
+		}
+		d := Division{Code: fields[0], Name: fields[1]}
+		if len(fields) > 2 {
+			d.NameEn = fields[2]
+		}
+		list = append(list, &d)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	t := divisions()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, d := range list {
+		// 保留内置的拼音
+		if old := t.byCode[d.Code]; old != nil && d.NameEn == "" {
+			d.NameEn = old.NameEn
+		}
+		t.add(d)
+	}
+	return nil
+}
+
+// fillAdcode 中国的结果根据名称补全 6 位行政区划代码，已是行政区划代码的字段保持不变
+// 其它格式的代码(如 ip-api 的 HB)会被替换，ISO 代码保留在 SubdivisionCode 中
+func (i *Info) fillAdcode() {
+	if i.CountryCode != "CN" {
+		return
+	}
+	if !isAdcode(i.RegionCode) {
+		region, city := FindAdcode(i.Region, i.City)
+		if region == "" && isAdcode(i.CityCode) {
+			region, _ = splitAdcode(i.CityCode)
+		}
+		if region != "" {
+			i.RegionCode = region
+		}
+		if !isAdcode(i.CityCode) && city != "" {
+			i.CityCode = city
+		}
+		return
+	}
+	if !isAdcode(i.CityCode) {
+		if _, city := FindAdcode(i.RegionCode, i.City); city != "" {
+			i.CityCode = city
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package geoip
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+)
+
+// restoreDivisions 测试结束后恢复全局的行政区划表，LoadDivisions 的修改不影响其它测试
+func restoreDivisions(t *testing.T) {
+	t.Helper()
+	d := divisions()
+	d.mu.RLock()
+	byCode, byName := maps.Clone(d.byCode), maps.Clone(d.byName)
+	d.mu.RUnlock()
+	t.Cleanup(func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.byCode, d.byName = byCode, byName
+	})
+}
+
+func TestFindAdcode(t *testing.T) {
+	cases := []struct {
+		region, city string
+		want         [2]string
+	}{
+		{"Hubei", "Wuhan", [2]string{"420000", "420100"}},
+		{"湖北省", "武汉", [2]string{"420000", "420100"}},
+		{"420000", "Jingmen City", [2]string{"420000", "420800"}},
+		{"Jiangsu", "Taizhou", [2]string{"320000", "321200"}},
+		{"Zhejiang", "Taizhou", [2]string{"330000", "331000"}},
+		{"吉林", "吉林市", [2]string{"220000", "220200"}},
+		{"湖北", "恩施州", [2]string{"420000", "422800"}},
+		{"Beijing", "Chaoyang", [2]string{"110000", "110000"}},
+		{"Hubei", "Unknown", [2]string{"420000", ""}},
+		{"Bavaria", "Munich", [2]string{"", ""}},
+	}
+	for _, c := range cases {
+		region, city := FindAdcode(c.region, c.city)
+		if region != c.want[0] || city != c.want[1] {
+			t.Fatalf("%s %s not match, got: %s %s", c.region, c.city, region, city)
+		}
+	}
+}
+
+func TestDivisionHierarchy(t *testing.T) {
+	if d, ok := LookupDivision("429004"); !ok || d.Name != "仙桃市" || d.NameEn != "Xiantao" {
+		t.Fatalf("division not match, got: %+v", d)
+	}
+
+	var got []string
+	for _, d := range DivisionHierarchy("420802") {
+		got = append(got, d.Name+"/"+d.NameEn)
+	}
+	if strings.Join(got, ",") != "湖北省/Hubei,荆门市/Jingmen,东宝区/Dongbao" {
+		t.Fatalf("hierarchy not match, got: %v", got)
+	}
+	if h := DivisionHierarchy("110105"); len(h) != 2 || h[0].Name != "北京市" || h[1].NameEn != "Chaoyang" {
+		t.Fatalf("municipality hierarchy not match, got: %+v", h)
+	}
+	if h := DivisionHierarchy("429021"); len(h) != 2 || h[1].Name != "神农架林区" {
+		t.Fatalf("province-administered hierarchy not match, got: %+v", h)
+	}
+
+	// 覆盖内置的记录并追加新的区县
+	restoreDivisions(t)
+	err := LoadDivisions(strings.NewReader("420802 东宝新区\n\n420899,测试区,Ceshi\n420800,荆门市\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := LookupDivision("420802"); d.Name != "东宝新区" || d.NameEn != "Dongbao" {
+		t.Fatalf("builtin english name must be kept, got: %+v", d)
+	}
+	if h := DivisionHierarchy("420899"); len(h) != 3 || h[2].NameEn != "Ceshi" {
+		t.Fatalf("loaded hierarchy not match, got: %+v", h)
+	}
+	if d, _ := LookupDivision("420800"); d.NameEn != "Jingmen" {
+		t.Fatalf("builtin english name must be kept, got: %+v", d)
+	}
+	if h := DivisionHierarchy("abc"); h != nil {
+		t.Fatalf("invalid adcode not match, got: %+v", h)
+	}
+
+	if err := LoadDivisions(strings.NewReader("4208 东宝区\n")); err == nil {
+		t.Fatal("expected error for invalid adcode")
+	}
+}
+
+func TestFillAdcode(t *testing.T) {
+	cases := []struct {
+		info   Info
+		region string
+		city   string
+	}{
+		// ip-api 返回 ISO 代码与拼音
+		{Info{CountryCode: "CN", Region: "Hubei", RegionCode: "HB", City: "Wuhan"}, "420000", "420100"},
+		// 已有的行政区划代码保持不变
+		{Info{CountryCode: "CN", Region: "湖北省", RegionCode: "420000", City: "荆门市", CityCode: "420800"}, "420000", "420800"},
+		{Info{CountryCode: "CN", RegionCode: "420000", City: "Xiangyang"}, "420000", "420600"},
+		{Info{CountryCode: "US", Region: "California", RegionCode: "CA"}, "CA", ""},
+	}
+	for _, c := range cases {
+		c.info.fillAdcode()
+		if c.info.RegionCode != c.region || c.info.CityCode != c.city {
+			t.Fatalf("%+v not match", c.info)
+		}
+	}
+
+	db, err := LoadCSV(strings.NewReader("183.95.0.0,183.95.255.255,China,Hubei,Jingmen,Unicom\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := New(English, WithHandlers(db)).Lookup(context.Background(), "183.95.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.RegionCode != "420000" || info.CityCode != "420800" || info.SubdivisionCode != "CN-HB" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+}
+
+func TestAdcodeTable(t *testing.T) {
+	rows := readEmbeddedCSV(cnAdcodesCSV)[1:]
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if len(row) != 4 || !isAdcode(row[0]) || row[1] == "" || row[2] == "" {
+			t.Fatalf("invalid row: %v", row)
+		}
+		if seen[row[0]] {
+			t.Fatalf("duplicate adcode: %s", row[0])
+		}
+		seen[row[0]] = true
+		if _, ok := LookupDivision(row[0][:2] + "0000"); !ok {
+			t.Fatalf("unknown province: %s", row[0])
+		}
+	}
+
+	rows = readEmbeddedCSV(cnDistrictsCSV)[1:]
+	if len(rows) < 2800 {
+		t.Fatalf("districts not complete, got: %d", len(rows))
+	}
+	for _, row := range rows {
+		if len(row) != 3 || !isAdcode(row[0]) || row[1] == "" || row[2] == "" {
+			t.Fatalf("invalid district row: %v", row)
+		}
+		if seen[row[0]] {
+			t.Fatalf("duplicate adcode: %s", row[0])
+		}
+		seen[row[0]] = true
+		if _, city := splitAdcode(row[0]); city == row[0] || !seen[city] && city[2:] != "0000" {
+			t.Fatalf("unknown city of district: %s", row[0])
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package geoip
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 内置的地址模板，可以通过名称传给 ParseAddressTemplate
+const (
+	// AddressChinese 中文习惯，省市连写后接运营商，中国以外的结果带上国家，如 湖北省荆门市 联通
+	AddressChinese = "{foreign_country}{region}{city} {carrier}"
+	// AddressWestern 西文习惯，由小到大，如 Jingmen, Hubei, China
+	AddressWestern = "{city}, {region}, {country}"
+	// AddressFull 国家、省、市与运营商，以空格分隔，如 中国 湖北省 荆门市 联通
+	AddressFull = "{country} {region} {city} {carrier}"
+)
+
+// addressTemplates 模板名称
+var addressTemplates = map[string]string{
+	"chinese": AddressChinese,
+	"western": AddressWestern,
+	"full":    AddressFull,
+}
+
+// addressFields 模板中可用的字段
+var addressFields = map[string]func(*Info) string{
+	"country":      func(i *Info) string { return i.Country },
+	"country_code": func(i *Info) string { return i.CountryCode },
+	"region":       func(i *Info) string { return i.Region },
+	"city":         func(i *Info) string { return i.City },
+	"postal":       func(i *Info) string { return i.Postal },
+	"isp":          func(i *Info) string { return i.ISP },
+	"org":          func(i *Info) string { return i.Org },
+	// foreign_country 中国的结果为空
+	"foreign_country": func(i *Info) string {
+		if i.CountryCode == "CN" {
+			return ""
+		}
+		return i.Country
+	},
+	// carrier 规范化的运营商名称，无法识别时使用 ISP
+	"carrier": func(i *Info) string {
+		if i.Carrier != nil {
+			return i.Carrier.Name
+		}
+		return i.ISP
+	},
+}
+
+// addressPart 模板中的一段，field 为空时是字面量
+type addressPart struct {
+	field string
+	text  string
+}
+
+// AddressTemplate 地址模板，参见 ParseAddressTemplate
+type AddressTemplate struct {
+	parts []addressPart
+	auto  bool
+}
+
+// ParseAddressTemplate 解析地址模板，s 可以是模板名称 chinese/western/full/auto，也可以是模板字符串
+//
+// 模板中的 {country}、{country_code}、{foreign_country}、{region}、{city}、{postal}、{isp}、{org}、{carrier} 会被替换为字段值
+// 字面量作为分隔符，只在两侧都有值时保留，连续的空字段使用最后一个分隔符
+// 与前一个字段相同的值会被跳过(如直辖市的省与市)
+// auto 根据名称是否包含汉字选择 chinese 或 western
+func ParseAddressTemplate(s string) (*AddressTemplate, error) {
+	if s == "auto" {
+		return &AddressTemplate{auto: true}, nil
+	}
+	if named, ok := addressTemplates[s]; ok {
+		s = named
+	}
+	var t AddressTemplate
+	for s != "" {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			t.parts = append(t.parts, addressPart{text: s})
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("address template: unclosed field at %q", s[start:])
+		}
+		field := s[start+1 : start+end]
+		if _, ok := addressFields[field]; !ok {
+			return nil, fmt.Errorf("address template: unknown field %q", field)
+		}
+		if start > 0 {
+			t.parts = append(t.parts, addressPart{text: s[:start]})
+		}
+		t.parts = append(t.parts, addressPart{field: field})
+		s = s[start+end+1:]
+	}
+	return &t, nil
+}
+
+// MustParseAddressTemplate 同 ParseAddressTemplate，出错时 panic
+func MustParseAddressTemplate(s string) *AddressTemplate {
+	t, err := ParseAddressTemplate(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+var (
+	addressChinese = MustParseAddressTemplate(AddressChinese)
+	addressWestern = MustParseAddressTemplate(AddressWestern)
+)
+
+// Format 按模板生成地址，空字段及其分隔符会被跳过
+func (t *AddressTemplate) Format(info *Info) string {
+	if t.auto {
+		if detectLanguage(info) == Chinese {
+			return addressChinese.Format(info)
+		}
+		return addressWestern.Format(info)
+	}
+
+	var b strings.Builder
+	var sep, last string
+	for _, p := range t.parts {
+		if p.field == "" {
+			sep = p.text
+			continue
+		}
+		v := strings.TrimSpace(addressFields[p.field](info))
+		if v == "" || v == last {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(v)
+		sep, last = "", v
+	}
+	return b.String()
+}
+
+// FormatAddress 按语言习惯生成地址，中文使用 AddressChinese，其它语言使用 AddressWestern
+func FormatAddress(info *Info, lang Language) string {
+	if baseLanguage(lang) == "zh" {
+		return addressChinese.Format(info)
+	}
+	return addressWestern.Format(info)
+}
@@ -0,0 +1,70 @@
+package geoip
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAddressTemplate(t *testing.T) {
+	jingmen := &Info{
+		Country: "中国", CountryCode: "CN", Region: "湖北省", City: "荆门市", ISP: "UNICOM",
+		Carrier: &Carrier{ID: "china-unicom", Name: "联通"},
+	}
+	beijing := &Info{Country: "China", CountryCode: "CN", Region: "Beijing", City: "Beijing"}
+	us := &Info{Country: "美国", CountryCode: "US", Region: "加利福尼亚州", City: "山景城", ISP: "Google"}
+
+	cases := []struct {
+		tmpl string
+		info *Info
+		want string
+	}{
+		{"chinese", jingmen, "湖北省荆门市 联通"},
+		{"chinese", us, "美国加利福尼亚州山景城 Google"},
+		{"western", beijing, "Beijing, China"},
+		{"western", &Info{Country: "China", City: "Wuhan"}, "Wuhan, China"},
+		{"full", jingmen, "中国 湖北省 荆门市 联通"},
+		{"auto", jingmen, "湖北省荆门市 联通"},
+		{"auto", beijing, "Beijing, China"},
+		{"{region} {city} / {isp}", jingmen, "湖北省 荆门市 / UNICOM"},
+		{"{region}/{city}", &Info{}, ""},
+	}
+	for _, c := range cases {
+		tmpl, err := ParseAddressTemplate(c.tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tmpl.Format(c.info); got != c.want {
+			t.Fatalf("%s not match, got: %q", c.tmpl, got)
+		}
+	}
+
+	for _, s := range []string{"{nope}", "{city"} {
+		if _, err := ParseAddressTemplate(s); err == nil {
+			t.Fatalf("%s: expected error", s)
+		}
+	}
+	if got := FormatAddress(jingmen, English); got != "荆门市, 湖北省, 中国" {
+		t.Fatalf("format address not match, got: %s", got)
+	}
+}
+
+func TestEngineAddressTemplate(t *testing.T) {
+	db, err := LoadCSV(strings.NewReader("183.95.0.0,183.95.255.255,中国,湖北省,荆门市,中国联通\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(Chinese, WithHandlers(db), WithLanguages(English), WithAddressTemplate(MustParseAddressTemplate("auto")))
+	info, err := e.Lookup(context.Background(), "183.95.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Address != "Jingmen, Hubei, China" {
+		t.Fatalf("address not match, got: %s", info.Address)
+	}
+
+	cfg := Config{Providers: []ProviderConfig{{Name: "pconline"}}, AddressTemplate: "{nope}"}
+	if _, err := NewFromConfig(&cfg); err == nil {
+		t.Fatal("expected invalid template error")
+	}
+}
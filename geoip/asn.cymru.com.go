@@ -0,0 +1,171 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// cymruOrigin origin.asn.cymru.com / origin6.asn.cymru.com 的 TXT 记录
+//
+//	"15169 | 8.8.8.0/24 | US | arin | 2014-03-14"
+//
+// 同一前缀由多个 AS 宣告时，第一个字段为空格分隔的多个 ASN
+type cymruOrigin struct {
+	ASN      int
+	Prefix   netip.Prefix
+	Country  string
+	Registry string
+}
+
+// parseCymruOrigin 解析 TXT 记录，多条记录时返回最长的前缀
+func parseCymruOrigin(txts []string) (cymruOrigin, bool) {
+	var out cymruOrigin
+	var found bool
+	for _, txt := range txts {
+		fields := splitCymru(txt)
+		if len(fields) < 4 {
+			continue
+		}
+		asns := strings.Fields(fields[0])
+		if len(asns) == 0 {
+			continue
+		}
+		asn, err := strconv.Atoi(asns[0])
+		if err != nil {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(fields[1])
+		if err != nil {
+			continue
+		}
+		if found && prefix.Bits() <= out.Prefix.Bits() {
+			continue
+		}
+		out = cymruOrigin{ASN: asn, Prefix: prefix, Country: strings.ToUpper(fields[2]), Registry: fields[3]}
+		found = true
+	}
+	return out, found
+}
+
+// parseCymruASName 解析 AS 名称记录，返回描述
+//
+//	"15169 | US | arin | 2000-03-30 | GOOGLE - Google LLC, US"
+func parseCymruASName(txts []string) string {
+	for _, txt := range txts {
+		if fields := splitCymru(txt); len(fields) >= 5 && fields[4] != "" {
+			return fields[4]
+		}
+	}
+	return ""
+}
+
+func splitCymru(txt string) []string {
+	fields := strings.Split(txt, "|")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// cymruOriginName 返回查询的域名，IPv4 按字节倒序，IPv6 按半字节倒序
+//
+//	8.8.8.8              8.8.8.8.origin.asn.cymru.com.
+//	2001:4860:4860::8888 8.8.8.8.0.0.0.0.0.0.0.0.0.0.0.0.0.6.8.4.0.6.8.4.1.0.0.2.origin6.asn.cymru.com.
+func cymruOriginName(addr netip.Addr) string {
+	addr = addr.Unmap()
+	var b strings.Builder
+	if addr.Is4() {
+		ip := addr.As4()
+		for i := len(ip) - 1; i >= 0; i-- {
+			b.WriteString(strconv.Itoa(int(ip[i])))
+			b.WriteByte('.')
+		}
+		b.WriteString("origin.asn.cymru.com.")
+		return b.String()
+	}
+	const hex = "0123456789abcdef"
+	ip := addr.As16()
+	for i := len(ip) - 1; i >= 0; i-- {
+		b.WriteByte(hex[ip[i]&0x0f])
+		b.WriteByte('.')
+		b.WriteByte(hex[ip[i]>>4])
+		b.WriteByte('.')
+	}
+	b.WriteString("origin6.asn.cymru.com.")
+	return b.String()
+}
+
+func init() {
+	Register(Provider{
+		Name:        "cymru",
+		Description: "Team Cymru IP to ASN mapping over DNS, no key required, country level only",
+		Languages:   []Language{English},
+		New:         func(Credentials) (IPer, error) { return NewCymru(nil), nil },
+	})
+}
+
+// Cymru implements IPer interface, resolves ASN, prefix, country and registry from Team Cymru DNS TXT records
+type Cymru struct {
+	resolver *net.Resolver
+	names    *Map[int, string] // AS 名称，变化很少，不过期
+}
+
+// NewCymru creates Cymru instance, resolver is nil to use net.DefaultResolver
+func NewCymru(resolver *net.Resolver) IPer {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Cymru{resolver: resolver, names: NewMap[int, string]()}
+}
+
+// Lookup retrieves IP geolocation information
+func (c *Cymru) Lookup(ctx context.Context, ip string) (*Info, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIP, ip)
+	}
+	txts, err := c.resolver.LookupTXT(ctx, cymruOriginName(addr))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, &NotFoundError{Provider: "cymru", IP: ip}
+		}
+		return nil, err
+	}
+	origin, ok := parseCymruOrigin(txts)
+	if !ok {
+		return nil, &NotFoundError{Provider: "cymru", IP: ip, Reason: fmt.Sprintf("unexpected answer %q", txts)}
+	}
+
+	name := c.asName(ctx, origin.ASN)
+	return &Info{
+		IP:          ip,
+		CountryCode: origin.Country,
+		ISP:         name,
+		ASN:         origin.ASN,
+		Org:         name,
+		Prefix:      origin.Prefix.String(),
+		Registry:    origin.Registry,
+	}, nil
+}
+
+// asName 查询 AS 的名称，失败时返回空，不影响前缀与国家的结果
+func (c *Cymru) asName(ctx context.Context, asn int) string {
+	if name, ok := c.names.Load(asn); ok {
+		return name
+	}
+	txts, err := c.resolver.LookupTXT(ctx, "AS"+strconv.Itoa(asn)+".asn.cymru.com.")
+	if err != nil {
+		return ""
+	}
+	name := parseCymruASName(txts)
+	if name != "" {
+		c.names.Store(asn, name)
+	}
+	return name
+}
@@ -1,6 +1,7 @@
 package geoip
 
 import (
+	"iter"
 	"time"
 )
 
@@ -31,3 +32,10 @@ func (g *IPCache) Get(ip string) (*Info, error) {
 func (g *IPCache) Set(ip string, info *Info) {
 	g.data.Store(ip, info, g.ttl)
 }
+
+// All 遍历缓存中未过期的查询结果，可用于 DBBuilder.AddAll 导出 Engine 查询过的数据
+func (g *IPCache) All() iter.Seq2[string, *Info] {
+	return func(yield func(string, *Info) bool) {
+		g.data.RangeUnexpired(yield)
+	}
+}
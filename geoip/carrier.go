@@ -0,0 +1,158 @@
+package geoip
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// carriersJSON 内置的运营商规则，参见 LoadCarrierRules
+//
+//go:embed data/carriers.json
+var carriersJSON []byte
+
+// Carrier 规范化后的运营商
+type Carrier struct {
+	ID   string // 运营商标识，如 china-unicom
+	Name string // 展示名称，如 联通、China Unicom
+}
+
+// CarrierRule 一条运营商规则，ASN 精确匹配，关键字按不区分大小写的子串匹配 ISP/Org
+type CarrierRule struct {
+	ID       string              `json:"id"`
+	Names    map[Language]string `json:"names"`    // 各语言的展示名称，缺失时使用 en，再缺失时使用 ID
+	ASNs     []int               `json:"asns"`     // 运营商的自治系统号
+	Keywords []string            `json:"keywords"` // ISP/Org 中出现的关键字，如 联通、china169
+}
+
+// name 返回指定语言的展示名称，没有完全匹配时按主语言匹配
+func (r *CarrierRule) name(lang Language) string {
+	if n := r.Names[lang]; n != "" {
+		return n
+	}
+	base := baseLanguage(lang)
+	for l, n := range r.Names {
+		if baseLanguage(l) == base && n != "" {
+			return n
+		}
+	}
+	if n := r.Names[English]; n != "" {
+		return n
+	}
+	return r.ID
+}
+
+// CarrierRules 运营商规则集
+// 关键字按规则的顺序匹配，较具体的规则(如 中华电信)需要放在较宽泛的规则(如 电信)之前
+type CarrierRules struct {
+	rules []CarrierRule
+	byASN map[int]*CarrierRule
+}
+
+// defaultCarrierRules 内置的国内运营商及主要的国际运营商
+var defaultCarrierRules = sync.OnceValue(func() *CarrierRules {
+	rules, err := parseCarrierRules(carriersJSON)
+	if err != nil {
+		panic("geoip: invalid embedded carrier rules: " + err.Error())
+	}
+	return rules
+})
+
+// LoadCarrierRules 读取 JSON 格式的运营商规则
+//
+//	[{"id": "china-unicom", "names": {"en": "China Unicom", "zh-CN": "联通"}, "asns": [4837], "keywords": ["联通", "unicom"]}]
+func LoadCarrierRules(r io.Reader) (*CarrierRules, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseCarrierRules(data)
+}
+
+// OpenCarrierRules 读取 JSON 格式的运营商规则文件，参见 LoadCarrierRules
+func OpenCarrierRules(path string) (*CarrierRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCarrierRules(data)
+}
+
+func parseCarrierRules(data []byte) (*CarrierRules, error) {
+	var rules []CarrierRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("carrier rules: %w", err)
+	}
+	c := CarrierRules{rules: rules, byASN: make(map[int]*CarrierRule)}
+	for i := range c.rules {
+		r := &c.rules[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("carrier rules: rule %d: missing id", i+1)
+		}
+		for j, k := range r.Keywords {
+			r.Keywords[j] = normalizeName(k)
+		}
+		for _, asn := range r.ASNs {
+			if _, ok := c.byASN[asn]; !ok {
+				c.byASN[asn] = r
+			}
+		}
+	}
+	return &c, nil
+}
+
+// Match 根据 ASN 与 ISP/Org 等名称匹配运营商，lang 为展示名称的语言
+// ASN 优先，其次按顺序匹配各名称，名称为 "AS4837" 形式时按 ASN 匹配
+func (c *CarrierRules) Match(asn int, lang Language, names ...string) (Carrier, bool) {
+	r := c.match(asn, names)
+	if r == nil {
+		return Carrier{}, false
+	}
+	return Carrier{ID: r.ID, Name: r.name(lang)}, true
+}
+
+func (c *CarrierRules) match(asn int, names []string) *CarrierRule {
+	if r := c.byASN[asn]; asn > 0 && r != nil {
+		return r
+	}
+	for _, name := range names {
+		if r := c.byASN[parseASN(name)]; r != nil {
+			return r
+		}
+		key := normalizeName(name)
+		if key == "" {
+			continue
+		}
+		for i := range c.rules {
+			for _, k := range c.rules[i].Keywords {
+				if k != "" && strings.Contains(key, k) {
+					return &c.rules[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// NormalizeCarrier 使用内置规则匹配运营商，例如
+// NormalizeCarrier(0, Chinese, "CHINA UNICOM China169 Backbone") 与 NormalizeCarrier(4837, Chinese) 均返回 {china-unicom 联通}
+func NormalizeCarrier(asn int, lang Language, names ...string) (Carrier, bool) {
+	return defaultCarrierRules().Match(asn, lang, names...)
+}
+
+// fillCarrier 依次使用自定义规则与内置规则，根据 ASN、ISP、Org 补全 Carrier
+func (i *Info) fillCarrier(rules *CarrierRules, lang Language) {
+	for _, c := range []*CarrierRules{rules, defaultCarrierRules()} {
+		if c == nil {
+			continue
+		}
+		if carrier, ok := c.Match(i.ASN, lang, i.ISP, i.Org); ok {
+			i.Carrier = &carrier
+			return
+		}
+	}
+}
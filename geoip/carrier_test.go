@@ -0,0 +1,73 @@
+package geoip
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCarrier(t *testing.T) {
+	cases := []struct {
+		asn   int
+		names []string
+		lang  Language
+		want  Carrier
+	}{
+		{0, []string{"联通"}, Chinese, Carrier{"china-unicom", "联通"}},
+		{0, []string{"CHINA UNICOM China169 Backbone"}, Chinese, Carrier{"china-unicom", "联通"}},
+		{0, []string{"China Unicom Hubei"}, English, Carrier{"china-unicom", "China Unicom"}},
+		{0, []string{"AS4837"}, English, Carrier{"china-unicom", "China Unicom"}},
+		{4134, nil, Chinese, Carrier{"china-telecom", "电信"}},
+		{0, []string{"", "Chinanet"}, Language("zh"), Carrier{"china-telecom", "电信"}},
+		{0, []string{"中华电信"}, English, Carrier{"chunghwa-telecom", "Chunghwa Telecom"}},
+		{0, []string{"China Mobile Communications Corporation"}, Chinese, Carrier{"china-mobile", "移动"}},
+		{0, []string{"CERNET"}, Chinese, Carrier{"cernet", "教育网"}},
+		{7922, []string{"电信"}, English, Carrier{"comcast", "Comcast"}},
+		{0, []string{"Telefónica de España"}, Japanese, Carrier{"telefonica", "Telefónica"}},
+	}
+	for _, c := range cases {
+		got, ok := NormalizeCarrier(c.asn, c.lang, c.names...)
+		if !ok || got != c.want {
+			t.Fatalf("%d %v not match, got: %+v", c.asn, c.names, got)
+		}
+	}
+	if got, ok := NormalizeCarrier(0, English, "Some Hosting LLC"); ok {
+		t.Fatalf("expected no match, got: %+v", got)
+	}
+}
+
+func TestCarrierRules(t *testing.T) {
+	if _, err := LoadCarrierRules(strings.NewReader(`[{"names":{"en":"x"}}]`)); err == nil {
+		t.Fatal("expected missing id error")
+	}
+
+	path := filepath.Join(t.TempDir(), "carriers.json")
+	data := `[{"id":"campus","names":{"zh-CN":"校园网"},"asns":[64512],"keywords":["Campus"]}]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := OpenCarrierRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := rules.Match(0, English, "XX CAMPUS NET"); !ok || got != (Carrier{"campus", "campus"}) {
+		t.Fatalf("custom rule not match, got: %+v", got)
+	}
+
+	db, err := LoadCSV(strings.NewReader("202.114.0.0,202.114.0.255,中国,湖北省,武汉市,校园网 campus\n202.114.1.0,202.114.1.255,中国,湖北省,武汉市,联通\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(Chinese, WithHandlers(db), WithCarrierRules(rules))
+	for ip, want := range map[string]string{"202.114.0.1": "校园网", "202.114.1.1": "联通"} {
+		info, err := e.Lookup(context.Background(), ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Carrier == nil || info.Carrier.Name != want {
+			t.Fatalf("%s carrier not match, got: %+v", ip, info.Carrier)
+		}
+	}
+}
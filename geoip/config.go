@@ -0,0 +1,167 @@
+package geoip
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "NETPULSE_"
+
+// Config Engine 配置，可以从 JSON/YAML/TOML 文件或环境变量加载
+//
+//	language: en
+//	languages: [zh-CN, en]
+//	carrier_rules: carriers.json
+//	address_template: auto
+//	reverse_dns: true
+//	providers:
+//	  - name: ipapi
+//	  - name: gaode
+//	    credentials:
+//	      key: your-key
+type Config struct {
+	Language  Language         `json:"language" yaml:"language" toml:"language"`
+	Languages []Language       `json:"languages" yaml:"languages" toml:"languages"` // 本地化名称的回退顺序，参见 WithLanguages
+	Providers []ProviderConfig `json:"providers" yaml:"providers" toml:"providers"`
+	// CarrierRules 自定义运营商规则文件，参见 OpenCarrierRules
+	CarrierRules string `json:"carrier_rules" yaml:"carrier_rules" toml:"carrier_rules"`
+	// AddressTemplate 地址模板名称或模板字符串，参见 ParseAddressTemplate
+	AddressTemplate string `json:"address_template" yaml:"address_template" toml:"address_template"`
+	// ReverseDNS 反向解析并正向确认主机名，参见 NewReverseDNS
+	ReverseDNS bool `json:"reverse_dns" yaml:"reverse_dns" toml:"reverse_dns"`
+}
+
+// ProviderConfig 按顺序使用的 provider
+// 未配置的凭据会从环境变量 NETPULSE_<NAME>_<CREDENTIAL> 读取
+type ProviderConfig struct {
+	Name        string      `json:"name" yaml:"name" toml:"name"`
+	Credentials Credentials `json:"credentials" yaml:"credentials" toml:"credentials"`
+}
+
+// LoadConfig 读取配置文件，根据扩展名 .json/.yaml/.yml/.toml 选择解析格式
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ParseConfig 解析配置，format 可选 json/yaml/yml/toml
+func ParseConfig(data []byte, format string) (*Config, error) {
+	var cfg Config
+	var err error
+	switch strings.ToLower(format) {
+	case "json":
+		err = json.Unmarshal(data, &cfg)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, &cfg)
+	case "toml":
+		err = toml.Unmarshal(data, &cfg)
+	default:
+		return nil, fmt.Errorf("unsupported config format: %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ConfigFromEnv 从环境变量读取配置
+//
+//	NETPULSE_LANGUAGE=en
+//	NETPULSE_LANGUAGES=zh-CN,en
+//	NETPULSE_PROVIDERS=ipapi,ipwho,gaode
+//	NETPULSE_CARRIER_RULES=carriers.json
+//	NETPULSE_ADDRESS_TEMPLATE=auto
+//	NETPULSE_REVERSE_DNS=true
+//	NETPULSE_GAODE_KEY=your-key
+func ConfigFromEnv() *Config {
+	cfg := Config{
+		Language:        Language(os.Getenv(envPrefix + "LANGUAGE")),
+		CarrierRules:    os.Getenv(envPrefix + "CARRIER_RULES"),
+		AddressTemplate: os.Getenv(envPrefix + "ADDRESS_TEMPLATE"),
+	}
+	cfg.ReverseDNS, _ = strconv.ParseBool(os.Getenv(envPrefix + "REVERSE_DNS"))
+	for lang := range strings.SplitSeq(os.Getenv(envPrefix+"LANGUAGES"), ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			cfg.Languages = append(cfg.Languages, Language(lang))
+		}
+	}
+	for name := range strings.SplitSeq(os.Getenv(envPrefix+"PROVIDERS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Providers = append(cfg.Providers, ProviderConfig{Name: name})
+		}
+	}
+	return &cfg
+}
+
+// NewFromConfig 根据配置创建 Engine
+// 未指定语言时使用 Chinese，未指定 provider 时使用该语言的默认 provider
+// 只有 English 与 Chinese 有默认 provider，其它语言需要配置 providers 或通过 opts 指定 WithHandlers
+// opts 在配置之后应用，可以覆盖配置中的 provider
+func NewFromConfig(cfg *Config, opts ...Option) (*Engine, error) {
+	language := cfg.Language
+	if language == "" {
+		language = Chinese
+	}
+
+	handlers := make([]IPer, 0, len(cfg.Providers))
+	for _, pc := range cfg.Providers {
+		p, ok := GetProvider(pc.Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, pc.Name)
+		}
+		iper, err := NewProvider(p.Name, credentialsFromEnv(p, pc.Credentials))
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, iper)
+	}
+	// 没有默认 provider 的语言必须显式配置，否则 Engine 无法返回任何结果
+	if len(handlers) == 0 && !hasDefaultHandlers(language) {
+		probe := Engine{language: language}
+		for _, opt := range opts {
+			opt(&probe)
+		}
+		if len(probe.handlers) == 0 {
+			return nil, fmt.Errorf("%w: language %q has no default providers; list providers explicitly", ErrNoProviders, language)
+		}
+	}
+	if len(handlers) > 0 {
+		opts = append([]Option{WithHandlers(handlers...)}, opts...)
+	}
+	if len(cfg.Languages) > 0 {
+		opts = append([]Option{WithLanguages(cfg.Languages...)}, opts...)
+	}
+	if cfg.CarrierRules != "" {
+		rules, err := OpenCarrierRules(cfg.CarrierRules)
+		if err != nil {
+			return nil, err
+		}
+		opts = append([]Option{WithCarrierRules(rules)}, opts...)
+	}
+	if cfg.AddressTemplate != "" {
+		t, err := ParseAddressTemplate(cfg.AddressTemplate)
+		if err != nil {
+			return nil, err
+		}
+		opts = append([]Option{WithAddressTemplate(t)}, opts...)
+	}
+	if cfg.ReverseDNS {
+		opts = append([]Option{WithEnrichers(NewReverseDNS(nil))}, opts...)
+	}
+	return New(language, opts...), nil
+}
+
+// NewFromEnv 根据环境变量创建 Engine，参见 ConfigFromEnv
+func NewFromEnv(opts ...Option) (*Engine, error) {
+	return NewFromConfig(ConfigFromEnv(), opts...)
+}
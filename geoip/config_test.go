@@ -0,0 +1,119 @@
+package geoip
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProviders(t *testing.T) {
+	for _, name := range []string{"ipapi", "freeipapi", "ifconfigco", "ipwho", "pconline", "gaode"} {
+		if _, ok := GetProvider(name); !ok {
+			t.Fatalf("provider %s not registered", name)
+		}
+	}
+	if _, err := NewProvider("gaode", nil); !errors.Is(err, ErrMissingCredential) {
+		t.Fatalf("expected ErrMissingCredential, got: %v", err)
+	}
+	if _, err := NewProvider("nope", nil); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("expected ErrUnknownProvider, got: %v", err)
+	}
+	if iper, err := NewProvider("gaode", Credentials{"key": "k"}); err != nil || iper.(*Gaode).key != "k" {
+		t.Fatalf("new gaode failed: %v", err)
+	}
+	if name := EnvName("ip2location.io", "key"); name != "NETPULSE_IP2LOCATION_IO_KEY" {
+		t.Fatalf("env name not match, got: %s", name)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cases := map[string]string{
+		"json": `{"language":"en","providers":[{"name":"ipapi"},{"name":"gaode","credentials":{"key":"k"}}]}`,
+		"yaml": "language: en\nproviders:\n  - name: ipapi\n  - name: gaode\n    credentials:\n      key: k\n",
+		"toml": "language = \"en\"\n[[providers]]\nname = \"ipapi\"\n[[providers]]\nname = \"gaode\"\ncredentials = { key = \"k\" }\n",
+	}
+	for format, data := range cases {
+		cfg, err := ParseConfig([]byte(data), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if cfg.Language != English || len(cfg.Providers) != 2 || cfg.Providers[1].Credentials["key"] != "k" {
+			t.Fatalf("%s: config not match, got: %+v", format, cfg)
+		}
+		e, err := NewFromConfig(cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(e.handlers) != 2 {
+			t.Fatalf("%s: handlers not match, got: %d", format, len(e.handlers))
+		}
+	}
+
+	// 没有默认 provider 的语言必须配置 provider
+	cfg, err := ParseConfig([]byte("language: ja\n"), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFromConfig(cfg); !errors.Is(err, ErrNoProviders) {
+		t.Fatalf("expected ErrNoProviders, got: %v", err)
+	}
+	if e, err := NewFromConfig(cfg, WithHandlers(NewIPapi())); err != nil || len(e.handlers) != 1 {
+		t.Fatalf("handlers from options not match, got: %v", err)
+	}
+	cfg.Providers = []ProviderConfig{{Name: "ipapi"}}
+	if e, err := NewFromConfig(cfg); err != nil || e.language != "ja" || len(e.handlers) != 1 {
+		t.Fatalf("explicit providers not match, got: %v", err)
+	}
+
+	if _, err := ParseConfig(nil, "ini"); err == nil {
+		t.Fatal("expected unsupported format error")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "netpulse.yml")
+	if err := os.WriteFile(path, []byte("providers:\n  - name: pconline\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.language != Chinese || len(e.handlers) != 1 {
+		t.Fatalf("engine not match, got: %s %d", e.language, len(e.handlers))
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("NETPULSE_LANGUAGE", "en")
+	t.Setenv("NETPULSE_PROVIDERS", "ipapi, ipwho,gaode")
+	t.Setenv("NETPULSE_GAODE_KEY", "")
+	if _, err := NewFromEnv(); !errors.Is(err, ErrMissingCredential) {
+		t.Fatalf("expected ErrMissingCredential, got: %v", err)
+	}
+
+	t.Setenv("NETPULSE_GAODE_KEY", "k")
+	e, err := NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.language != English || len(e.handlers) != 3 {
+		t.Fatalf("engine not match, got: %s %d", e.language, len(e.handlers))
+	}
+	if g, ok := e.handlers[2].(*Gaode); !ok || g.key != "k" {
+		t.Fatal("gaode key not loaded from env")
+	}
+	if len(e.enrichers) != 0 {
+		t.Fatalf("reverse dns should be disabled by default, got: %d", len(e.enrichers))
+	}
+
+	t.Setenv("NETPULSE_REVERSE_DNS", "true")
+	if e, err = NewFromEnv(); err != nil || len(e.enrichers) != 1 {
+		t.Fatalf("reverse dns not match, got: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CSVColumns CSV 列映射，值为从 1 开始的列号，0 表示不存在该列
+type CSVColumns struct {
+	Start       int // 起始 IP，点分/冒号格式或十进制数字
+	End         int // 结束 IP，格式同 Start
+	Country     int // 国家名称
+	CountryCode int // ISO 3166-1 二位国家代码
+	Region      int // 省份/州
+	City        int // 城市
+	ISP         int // 运营商
+	Latitude    int // 纬度
+	Longitude   int // 经度
+	Postal      int // 邮政编码
+}
+
+// 常见 CSV 格式的列映射
+var (
+	// CSVSimple start,end,country,region,city,isp
+	CSVSimple = CSVColumns{Start: 1, End: 2, Country: 3, Region: 4, City: 5, ISP: 6}
+	// CSVDBIPCityLite DB-IP IP to City Lite
+	// ip_start,ip_end,continent,country,stateprov,city,latitude,longitude
+	CSVDBIPCityLite = CSVColumns{Start: 1, End: 2, CountryCode: 4, Region: 5, City: 6, Latitude: 7, Longitude: 8}
+	// CSVIP2LocationLite IP2Location LITE DB11，IP 为十进制数字
+	// ip_from,ip_to,country_code,country_name,region_name,city_name,latitude,longitude,zip_code,time_zone
+	CSVIP2LocationLite = CSVColumns{Start: 1, End: 2, CountryCode: 3, Country: 4, Region: 5, City: 6, Latitude: 7, Longitude: 8, Postal: 9}
+)
+
+// CSVOptions CSV 解析参数
+type CSVOptions struct {
+	Columns CSVColumns // 列映射，为空时使用 CSVSimple
+	Comma   rune       // 分隔符，默认 ','
+	Comment rune       // 注释行前缀，0 表示不支持注释
+	Header  bool       // 是否跳过首行表头
+}
+
+// OpenCSV 读取 CSV 文件创建 RangeDB
+func OpenCSV(path string, opts CSVOptions) (*RangeDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadCSV(f, opts)
+}
+
+// LoadCSV 读取 IP 段 CSV 创建 RangeDB，支持 IPv4/IPv6，点分格式或十进制数字
+// 所有字段均为空或 "-" 的行会被忽略，IP 段重叠时返回错误
+func LoadCSV(r io.Reader, opts CSVOptions) (*RangeDB, error) {
+	cols := opts.Columns
+	if cols == (CSVColumns{}) {
+		cols = CSVSimple
+	}
+	if cols.Start == 0 || cols.End == 0 {
+		return nil, errors.New("csv: start and end columns are required")
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.Comment = opts.Comment
+
+	var ranges []ipRange
+	// 相同位置信息的 IP 段共享同一个 *Info
+	infos := make(map[csvRecord]*Info)
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if first && opts.Header {
+			continue
+		}
+
+		field := func(col int) string {
+			if col <= 0 || col > len(record) {
+				return ""
+			}
+			v := strings.TrimSpace(record[col-1])
+			if v == "-" {
+				return ""
+			}
+			return v
+		}
+
+		startField := field(cols.Start)
+		start, err := parseRangeAddr(startField)
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+		end, err := parseRangeAddr(field(cols.End))
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d: %w", line, err)
+		}
+		// IPv6 数据库中的 IPv4 段以 ::ffff:a.b.c.d 表示
+		if start.Is4In6() && end.Is4In6() {
+			start, end = start.Unmap(), end.Unmap()
+		}
+		// 十进制格式的 IPv6 段起始值可能小于 2^32
+		if start.Is4() && end.Is6() && !strings.ContainsAny(startField, ".:") {
+			v4 := start.As4()
+			start = netip.AddrFrom16([16]byte{12: v4[0], 13: v4[1], 14: v4[2], 15: v4[3]})
+		}
+
+		rec := csvRecord{
+			country:     field(cols.Country),
+			countryCode: field(cols.CountryCode),
+			region:      field(cols.Region),
+			city:        field(cols.City),
+			isp:         field(cols.ISP),
+			latitude:    field(cols.Latitude),
+			longitude:   field(cols.Longitude),
+			postal:      field(cols.Postal),
+		}
+		if rec == (csvRecord{}) {
+			continue
+		}
+		p, ok := infos[rec]
+		if !ok {
+			p = rec.toInfo()
+			infos[rec] = p
+		}
+		ranges = append(ranges, ipRange{start: start, end: end, info: p, line: line})
+	}
+	return newRangeDB(ranges)
+}
+
+// csvRecord 一行 CSV 中的位置信息
+type csvRecord struct {
+	country, countryCode, region, city, isp string
+	latitude, longitude, postal             string
+}
+
+func (r csvRecord) toInfo() *Info {
+	info := Info{
+		Country:     r.country,
+		CountryCode: r.countryCode,
+		Region:      r.region,
+		City:        r.city,
+		ISP:         r.isp,
+		Postal:      r.postal,
+		Address:     joinNonEmpty(" ", r.country, r.region, r.city, r.isp),
+	}
+	info.Latitude, _ = strconv.ParseFloat(r.latitude, 64)
+	info.Longitude, _ = strconv.ParseFloat(r.longitude, 64)
+	return &info
+}
+
+// parseRangeAddr 解析点分/冒号格式或十进制数字格式的 IP
+func parseRangeAddr(s string) (netip.Addr, error) {
+	if s == "" {
+		return netip.Addr{}, errors.New("empty ip")
+	}
+	if strings.ContainsAny(s, ".:") {
+		return netip.ParseAddr(s)
+	}
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 128 {
+		return netip.Addr{}, fmt.Errorf("invalid ip number %q", s)
+	}
+	var b [16]byte
+	n.FillBytes(b[:])
+	return netip.AddrFrom16(b), nil
+}
+
+// joinNonEmpty 使用 sep 拼接非空字符串
+func joinNonEmpty(sep string, elems ...string) string {
+	out := make([]string, 0, len(elems))
+	for _, e := range elems {
+		if e != "" {
+			out = append(out, e)
+		}
+	}
+	return strings.Join(out, sep)
+}
@@ -0,0 +1,77 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestCymruOriginName(t *testing.T) {
+	for ip, want := range map[string]string{
+		"8.8.4.4":              "4.4.8.8.origin.asn.cymru.com.",
+		"::ffff:1.2.3.4":       "4.3.2.1.origin.asn.cymru.com.",
+		"2001:4860:4860::8888": "8.8.8.8.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.6.8.4.0.6.8.4.1.0.0.2.origin6.asn.cymru.com.",
+	} {
+		if got := cymruOriginName(netip.MustParseAddr(ip)); got != want {
+			t.Fatalf("origin name of %s not match, got: %s", ip, got)
+		}
+	}
+}
+
+func TestCymru(t *testing.T) {
+	resolver := serveDNS(t, map[string][]string{
+		"TXT 8.8.8.8.origin.asn.cymru.com.": {"15169 | 8.8.8.0/24 | US | arin | 2014-03-14"},
+		// 多条记录时使用最长的前缀，多个 ASN 时使用第一个
+		"TXT 1.1.95.183.origin.asn.cymru.com.":                                                       {"4837 | 183.92.0.0/14 | CN | apnic | 2010-05-26", "4837 9929 | 183.95.0.0/16 | CN | apnic | 2010-05-26"},
+		"TXT 8.8.8.8.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.6.8.4.0.6.8.4.1.0.0.2.origin6.asn.cymru.com.": {"15169 | 2001:4860::/32 | US | arin | 2005-03-14"},
+		"TXT as15169.asn.cymru.com.":                                                                 {"15169 | US | arin | 2000-03-30 | GOOGLE - Google LLC, US"},
+		"TXT 4.3.2.1.origin.asn.cymru.com.":                                                          {"garbage"},
+	})
+	c := NewCymru(resolver)
+	ctx := context.Background()
+
+	info, err := c.Lookup(ctx, "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ASN != 15169 || info.Prefix != "8.8.8.0/24" || info.CountryCode != "US" || info.Registry != "arin" || info.Org != "GOOGLE - Google LLC, US" {
+		t.Fatalf("ipv4 not match, got: %+v", info)
+	}
+
+	info, err = c.Lookup(ctx, "183.95.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// AS 名称查询失败不影响结果
+	if info.ASN != 4837 || info.Prefix != "183.95.0.0/16" || info.CountryCode != "CN" || info.Org != "" {
+		t.Fatalf("multiple records not match, got: %+v", info)
+	}
+
+	info, err = c.Lookup(ctx, "2001:4860:4860::8888")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ASN != 15169 || info.Prefix != "2001:4860::/32" || info.ISP != "GOOGLE - Google LLC, US" {
+		t.Fatalf("ipv6 not match, got: %+v", info)
+	}
+
+	for _, ip := range []string{"9.9.9.9", "1.2.3.4"} {
+		if _, err := c.Lookup(ctx, ip); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("%s not found not match, got: %v", ip, err)
+		}
+	}
+	if _, err := c.Lookup(ctx, "nope"); !errors.Is(err, ErrInvalidIP) {
+		t.Fatalf("invalid ip not match, got: %v", err)
+	}
+
+	// Engine 根据国家代码补全国家名称
+	e := New(English, WithHandlers(c))
+	info, err = e.Lookup(ctx, "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Country != "United States" {
+		t.Fatalf("engine not match, got: %+v", info)
+	}
+}
@@ -0,0 +1,229 @@
+package geoip
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/netip"
+	"slices"
+)
+
+// DBBuilder 将 IP/网段与 Info 的对应关系构建为离线数据库
+// 相邻且信息相同的网段会被合并，结果可以通过 Build 直接使用，或 WriteTo 写出后由 LoadDB/OpenDB 加载
+// 仅保存 Info 的基础字段，Privacy/Company/Abuse/Bounds 等嵌套信息不会写入
+type DBBuilder struct {
+	strs    map[string]uint64
+	strList []string
+
+	infos    map[string]int
+	infoList [][]byte
+
+	entries []dbEntry
+}
+
+type dbEntry struct {
+	start, end netip.Addr
+	info       int
+}
+
+// NewDBBuilder 创建 DBBuilder
+func NewDBBuilder() *DBBuilder {
+	return &DBBuilder{
+		strs:  make(map[string]uint64),
+		infos: make(map[string]int),
+	}
+}
+
+// AddRange 添加 [start, end] 区间
+func (b *DBBuilder) AddRange(start, end netip.Addr, info *Info) error {
+	start, end = start.Unmap(), end.Unmap()
+	if !start.IsValid() || !end.IsValid() {
+		return errors.New("invalid range address")
+	}
+	if start.Is4() != end.Is4() {
+		return fmt.Errorf("%s-%s mixes ipv4 and ipv6", start, end)
+	}
+	if start.Compare(end) > 0 {
+		return fmt.Errorf("start %s is greater than end %s", start, end)
+	}
+	if info == nil {
+		return errors.New("info is nil")
+	}
+	b.entries = append(b.entries, dbEntry{start: start, end: end, info: b.intern(info)})
+	return nil
+}
+
+// AddPrefix 添加网段
+func (b *DBBuilder) AddPrefix(prefix netip.Prefix, info *Info) error {
+	if !prefix.IsValid() {
+		return errors.New("invalid prefix")
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	prefix = prefix.Masked()
+	return b.AddRange(prefix.Addr(), lastAddr(prefix), info)
+}
+
+// AddIP 添加单个 IP 或 CIDR 格式的网段
+func (b *DBBuilder) AddIP(ip string, info *Info) error {
+	if prefix, err := netip.ParsePrefix(ip); err == nil {
+		return b.AddPrefix(prefix, info)
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return fmt.Errorf("invalid ip: %w", err)
+	}
+	return b.AddRange(addr, addr, info)
+}
+
+// AddAll 批量添加，例如 IPCache.All() 中 Engine 查询过的结果
+func (b *DBBuilder) AddAll(seq iter.Seq2[string, *Info]) error {
+	for ip, info := range seq {
+		if err := b.AddIP(ip, info); err != nil {
+			return fmt.Errorf("%s: %w", ip, err)
+		}
+	}
+	return nil
+}
+
+func (b *DBBuilder) intern(info *Info) int {
+	data := encodeInfo(info, func(s string) uint64 {
+		idx, ok := b.strs[s]
+		if !ok {
+			idx = uint64(len(b.strList))
+			b.strs[s] = idx
+			b.strList = append(b.strList, s)
+		}
+		return idx
+	})
+	idx, ok := b.infos[string(data)]
+	if !ok {
+		idx = len(b.infoList)
+		b.infos[string(data)] = idx
+		b.infoList = append(b.infoList, data)
+	}
+	return idx
+}
+
+// merged 排序并合并网段，信息相同的相邻或重叠网段合并为一段，信息不同的网段重叠时返回错误
+func (b *DBBuilder) merged() ([]dbEntry, error) {
+	entries := slices.Clone(b.entries)
+	slices.SortFunc(entries, func(x, y dbEntry) int {
+		if c := x.start.Compare(y.start); c != 0 {
+			return c
+		}
+		return x.end.Compare(y.end)
+	})
+
+	out := make([]dbEntry, 0, len(entries))
+	for _, e := range entries {
+		if len(out) == 0 {
+			out = append(out, e)
+			continue
+		}
+		prev := &out[len(out)-1]
+		overlap := e.start.Compare(prev.end) <= 0
+		if prev.info == e.info && (overlap || prev.end.Next() == e.start) {
+			if e.end.Compare(prev.end) > 0 {
+				prev.end = e.end
+			}
+			continue
+		}
+		if overlap {
+			return nil, fmt.Errorf("%s-%s overlaps %s-%s with different info", e.start, e.end, prev.start, prev.end)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// Build 构建内存中的 RangeDB，查询结果与 WriteTo 写出后加载的结果一致
+func (b *DBBuilder) Build() (*RangeDB, error) {
+	entries, err := b.merged()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]*Info, len(b.infoList))
+	for i, data := range b.infoList {
+		r := npdbReader{data: data}
+		infos[i] = r.info(b.strList)
+		if r.err != nil {
+			return nil, r.err
+		}
+	}
+	ranges := make([]ipRange, len(entries))
+	for i, e := range entries {
+		ranges[i] = ipRange{start: e.start, end: e.end, info: infos[e.info], line: i + 1}
+	}
+	return newRangeDB(ranges)
+}
+
+// WriteTo 写出数据库，implements io.WriterTo
+func (b *DBBuilder) WriteTo(w io.Writer) (int64, error) {
+	entries, err := b.merged()
+	if err != nil {
+		return 0, err
+	}
+
+	cw := countWriter{w: bufio.NewWriter(w)}
+	var buf []byte
+	uvarint := func(v uint64) {
+		buf = binary.AppendUvarint(buf[:0], v)
+		cw.Write(buf)
+	}
+
+	cw.Write([]byte(npdbMagic))
+	cw.Write([]byte{npdbVersion})
+	uvarint(uint64(len(b.strList)))
+	for _, s := range b.strList {
+		uvarint(uint64(len(s)))
+		cw.Write([]byte(s))
+	}
+	uvarint(uint64(len(b.infoList)))
+	for _, data := range b.infoList {
+		cw.Write(data)
+	}
+	uvarint(uint64(len(entries)))
+	for _, e := range entries {
+		start, end := e.start.AsSlice(), e.end.AsSlice()
+		cw.Write([]byte{byte(len(start))})
+		cw.Write(start)
+		cw.Write([]byte{byte(len(end))})
+		cw.Write(end)
+		uvarint(uint64(e.info))
+	}
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// countWriter 记录写入字节数与第一个错误
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countWriter) Write(p []byte) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+}
+
+// lastAddr 返回网段的最后一个地址
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
@@ -0,0 +1,133 @@
+package geoip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDBBuilder(t *testing.T) {
+	jingmen := &Info{Country: "中国", Region: "湖北省", City: "荆门市", ISP: "联通", ASN: 4837, Latitude: 31.03, Longitude: 112.2}
+	brisbane := &Info{Country: "Australia", CountryCode: "AU", City: "Brisbane", Privacy: &Privacy{VPN: true}}
+
+	b := NewDBBuilder()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(b.AddPrefix(netip.MustParsePrefix("183.95.0.0/17"), jingmen))
+	must(b.AddPrefix(netip.MustParsePrefix("183.95.128.0/17"), &Info{IP: "183.95.128.1", Country: "中国", Region: "湖北省", City: "荆门市", ISP: "联通", ASN: 4837, Latitude: 31.03, Longitude: 112.2}))
+	must(b.AddIP("183.95.1.1", jingmen))
+	must(b.AddIP("1.0.0.0/24", brisbane))
+	must(b.AddIP("1.0.1.0", brisbane))
+	must(b.AddIP("2001:db8::/32", &Info{Country: "Example"}))
+	must(b.AddPrefix(netip.MustParsePrefix("::ffff:8.8.8.0/120"), &Info{Org: "Google"}))
+
+	db, err := b.Build()
+	must(err)
+	// 183.95.0.0/16 合并为一段，1.0.0.0/24 与 1.0.1.0 合并为一段
+	if db.Len() != 4 {
+		t.Fatalf("len not match, got: %d", db.Len())
+	}
+
+	var buf bytes.Buffer
+	n, err := b.WriteTo(&buf)
+	must(err)
+	if n != int64(buf.Len()) {
+		t.Fatalf("written size not match, got: %d, want: %d", n, buf.Len())
+	}
+	loaded, err := LoadDB(&buf)
+	must(err)
+	if loaded.Len() != db.Len() {
+		t.Fatalf("loaded len not match, got: %d", loaded.Len())
+	}
+
+	for _, d := range []*RangeDB{db, loaded} {
+		info, err := d.Lookup(context.Background(), "183.95.200.1")
+		must(err)
+		if info.IP != "183.95.200.1" || info.City != "荆门市" || info.ASN != 4837 || info.Latitude != 31.03 || info.Longitude != 112.2 {
+			t.Fatalf("info not match, got: %+v", info)
+		}
+		info, err = d.Lookup(context.Background(), "1.0.1.0")
+		must(err)
+		if info.CountryCode != "AU" || info.Privacy != nil {
+			t.Fatalf("info not match, got: %+v", info)
+		}
+		if info, err := d.Lookup(context.Background(), "8.8.8.8"); err != nil || info.Org != "Google" {
+			t.Fatalf("mapped prefix lookup failed: %v %+v", err, info)
+		}
+		if info, err := d.Lookup(context.Background(), "2001:db8:ffff::1"); err != nil || info.Country != "Example" {
+			t.Fatalf("ipv6 lookup failed: %v %+v", err, info)
+		}
+		if _, err := d.Lookup(context.Background(), "1.0.1.1"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected not found, got: %v", err)
+		}
+	}
+
+	must(b.AddIP("183.95.1.0/24", &Info{ISP: "电信"}))
+	if _, err := b.Build(); err == nil {
+		t.Fatal("expected overlap error")
+	}
+}
+
+func TestDBBuilderFromCache(t *testing.T) {
+	cache := NewGeoIPCache(time.Minute)
+	info := &Info{Country: "中国", Region: "湖北省"}
+	cache.Set("183.95.1.1", info)
+	cache.Set("183.95.1.2", info)
+	cache.Set("183.95.1.4", info)
+	// 过期的结果不导出，否则 183.95.1.1-183.95.1.4 会合并为一个网段
+	cache.(*IPCache).data.Store("183.95.1.3", info, -time.Second)
+
+	b := NewDBBuilder()
+	if err := b.AddAll(cache.(*IPCache).All()); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "geo.npdb")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.WriteTo(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 2 {
+		t.Fatalf("len not match, got: %d", db.Len())
+	}
+	if _, err := db.Lookup(context.Background(), "183.95.1.3"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got: %v", err)
+	}
+}
+
+func TestLoadDBInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewDBBuilder()
+	if err := b.AddIP("1.1.1.1", &Info{Country: "Australia"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	for i := range data {
+		if _, err := LoadDB(bytes.NewReader(data[:i])); err == nil {
+			t.Fatalf("expected error for truncated data at %d", i)
+		}
+	}
+	if _, err := LoadDB(bytes.NewReader([]byte("XXXX\x01"))); err == nil {
+		t.Fatal("expected magic error")
+	}
+}
@@ -0,0 +1,109 @@
+package geoip
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// dnsTypes 测试 DNS 服务支持的记录类型
+var dnsTypes = map[string]uint16{"A": 1, "PTR": 12, "TXT": 16, "AAAA": 28}
+
+// serveDNS 在本地启动 DNS 服务，records 的键为 "类型 域名"，如 "TXT 8.8.8.8.origin.asn.cymru.com."
+// 域名没有任何记录时返回 NXDOMAIN
+func serveDNS(t *testing.T, records map[string][]string) *net.Resolver {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := dnsAnswer(buf[:n], records); resp != nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", conn.LocalAddr().String())
+		},
+	}
+}
+
+func dnsAnswer(query []byte, records map[string][]string) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+	// question 中的域名
+	var labels []string
+	i := 12
+	for i < len(query) && query[i] != 0 {
+		l := int(query[i])
+		if i+1+l > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[i+1:i+1+l]))
+		i += 1 + l
+	}
+	end := i + 5 // 结束的 0 与 qtype、qclass
+	if end > len(query) {
+		return nil
+	}
+	name := strings.ToLower(strings.Join(labels, ".")) + "."
+	qtype := binary.BigEndian.Uint16(query[i+1:])
+
+	exists := false
+	var values []string
+	for typ, code := range dnsTypes {
+		v, ok := records[typ+" "+name]
+		exists = exists || ok
+		if code == qtype {
+			values = v
+		}
+	}
+
+	resp := append([]byte{}, query[:2]...)
+	flags := uint16(0x8180)
+	if !exists {
+		flags |= 3 // NXDOMAIN
+	}
+	resp = binary.BigEndian.AppendUint16(resp, flags)
+	resp = binary.BigEndian.AppendUint16(resp, 1)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(values)))
+	resp = append(resp, 0, 0, 0, 0)
+	resp = append(resp, query[12:end]...)
+	for _, v := range values {
+		var rdata []byte
+		switch qtype {
+		case dnsTypes["TXT"]:
+			rdata = append([]byte{byte(len(v))}, v...)
+		case dnsTypes["PTR"]:
+			for label := range strings.SplitSeq(strings.TrimSuffix(v, "."), ".") {
+				rdata = append(rdata, byte(len(label)))
+				rdata = append(rdata, label...)
+			}
+			rdata = append(rdata, 0)
+		default:
+			rdata = netip.MustParseAddr(v).AsSlice()
+		}
+		resp = append(resp, 0xc0, 12) // 指向 question 中的域名
+		resp = binary.BigEndian.AppendUint16(resp, qtype)
+		resp = append(resp, 0, 1, 0, 0, 0x0e, 0x10) // IN TTL=3600
+		resp = binary.BigEndian.AppendUint16(resp, uint16(len(rdata)))
+		resp = append(resp, rdata...)
+	}
+	return resp
+}
@@ -1,12 +1,93 @@
 package geoip
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
 
 var (
-	ErrPrivateIP = errors.New("private ip")
-	ErrNotFound  = errors.New("not found")
+	ErrPrivateIP         = errors.New("private ip")
+	ErrInvalidIP         = errors.New("invalid ip")
+	ErrNotFound          = errors.New("not found")
+	ErrUnknownProvider   = errors.New("unknown provider")
+	ErrMissingCredential = errors.New("missing credential")
+	ErrQuotaExceeded     = errors.New("quota exceeded")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrNoProviders       = errors.New("no providers")
 )
 
 func IsErrPrivateIP(err error) bool {
 	return errors.Is(err, ErrPrivateIP)
 }
+
+// IsErrQuotaExceeded 服务商返回 429 或在响应中声明额度已用完
+func IsErrQuotaExceeded(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded)
+}
+
+// IsErrUnauthorized 凭据无效或无权访问
+func IsErrUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// NotFoundError provider 没有该 IP 的数据，例如国内服务商查询国外 IP
+// 可以通过 errors.Is(err, ErrNotFound) 判断
+type NotFoundError struct {
+	Provider string
+	IP       string
+	Reason   string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s not found: %s", e.Provider, e.IP, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s not found", e.Provider, e.IP)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// StatusError 服务商返回非 200 状态码
+// 可以通过 errors.Is 判断 ErrQuotaExceeded/ErrUnauthorized/ErrNotFound
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // 来自 Retry-After 响应头，未提供时为 0
+	Body       []byte        // 响应体，最多保留 1KB，便于 provider 进一步归类
+	Err        error         // 按状态码归类的错误，无法归类时为 nil
+}
+
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("status code: %d: %s", e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("status code: %d", e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// NewStatusError 根据非 200 响应创建 StatusError，按状态码归类并读取 Retry-After 与最多 1KB 的响应体
+// 供 geoip 之外的 provider 使用，使错误的判断方式与内置 provider 一致
+func NewStatusError(resp *http.Response) *StatusError {
+	e := StatusError{StatusCode: resp.StatusCode}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusPaymentRequired:
+		e.Err = ErrQuotaExceeded
+	case http.StatusUnauthorized, http.StatusForbidden:
+		e.Err = ErrUnauthorized
+	case http.StatusNotFound:
+		e.Err = ErrNotFound
+	}
+	if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(sec) * time.Second
+	}
+	e.Body, _ = io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &e
+}
@@ -0,0 +1,196 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ IPer = (*FileDB)(nil)
+
+// LoadFunc 从文件加载离线数据库，例如
+//
+//	func(path string) (IPer, error) { return OpenQQWry(path) }
+type LoadFunc func(path string) (IPer, error)
+
+// FileDB 基于文件的离线数据库，定期检测文件变化并在后台热加载
+// 新版本通过校验后原子替换，加载或校验失败时继续使用旧版本，查询不会中断
+// 更新文件时建议先写入临时文件再 rename，避免加载到写了一半的文件
+type FileDB struct {
+	path string
+	load LoadFunc
+
+	interval time.Duration
+	sanity   []string
+	onReload func(IPer)
+	onError  func(error)
+
+	current atomic.Pointer[fileVersion]
+	mu      sync.Mutex // 串行化加载
+	// 最近一次尝试加载的文件状态，加载失败时不会重复加载同一版本
+	attempted fileStat
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// fileVersion 已加载的数据库及其文件状态
+type fileVersion struct {
+	db   IPer
+	stat fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// FileDBOption FileDB 参数
+type FileDBOption func(*FileDB)
+
+// WithReloadInterval 设置文件检测间隔，默认 1 分钟，小于等于 0 时不自动检测
+func WithReloadInterval(d time.Duration) FileDBOption {
+	return func(f *FileDB) {
+		f.interval = d
+	}
+}
+
+// WithSanityIPs 设置校验 IP，新版本需全部查询成功才会替换旧版本
+func WithSanityIPs(ips ...string) FileDBOption {
+	return func(f *FileDB) {
+		f.sanity = append([]string(nil), ips...)
+	}
+}
+
+// WithOnReload 新版本替换成功后回调
+func WithOnReload(fn func(IPer)) FileDBOption {
+	return func(f *FileDB) {
+		f.onReload = fn
+	}
+}
+
+// WithOnReloadError 检测或加载失败时回调，此时仍在使用旧版本
+func WithOnReloadError(fn func(error)) FileDBOption {
+	return func(f *FileDB) {
+		f.onError = fn
+	}
+}
+
+// NewFileDB 同步加载 path 并启动后台检测，首次加载或校验失败时返回错误
+func NewFileDB(path string, load LoadFunc, opts ...FileDBOption) (*FileDB, error) {
+	f := FileDB{
+		path:     path,
+		load:     load,
+		interval: time.Minute,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&f)
+	}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	if f.interval > 0 {
+		go f.watch(ctx)
+	} else {
+		close(f.done)
+	}
+	return &f, nil
+}
+
+// Lookup implements IPer.
+func (f *FileDB) Lookup(ctx context.Context, ip string) (*Info, error) {
+	return f.current.Load().db.Lookup(ctx, ip)
+}
+
+// DB 返回当前使用的数据库
+func (f *FileDB) DB() IPer {
+	return f.current.Load().db
+}
+
+// Reload 立即加载文件，无论文件是否变化
+// 加载或校验失败时返回错误并保留旧版本
+func (f *FileDB) Reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	st, err := statFile(f.path)
+	if err != nil {
+		return err
+	}
+	return f.reload(st)
+}
+
+func (f *FileDB) reload(st fileStat) error {
+	f.attempted = st
+	db, err := f.load(f.path)
+	if err != nil {
+		return fmt.Errorf("load %s: %w", f.path, err)
+	}
+	for _, ip := range f.sanity {
+		if _, err := db.Lookup(context.Background(), ip); err != nil {
+			return fmt.Errorf("sanity check %s on %s: %w", ip, f.path, err)
+		}
+	}
+	f.current.Store(&fileVersion{db: db, stat: st})
+	if f.onReload != nil {
+		f.onReload(db)
+	}
+	return nil
+}
+
+// check 文件状态与最近一次尝试加载时不同时重新加载
+func (f *FileDB) check() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	st, err := statFile(f.path)
+	if err != nil {
+		return err
+	}
+	if st == f.attempted {
+		return nil
+	}
+	return f.reload(st)
+}
+
+func (f *FileDB) watch(ctx context.Context) {
+	defer close(f.done)
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.check(); err != nil && f.onError != nil {
+				f.onError(err)
+			}
+		}
+	}
+}
+
+// Close 停止后台检测，已加载的数据库仍可查询
+func (f *FileDB) Close() error {
+	f.cancel()
+	<-f.done
+	return nil
+}
+
+func statFile(path string) (fileStat, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}, err
+	}
+	if fi.IsDir() {
+		return fileStat{}, errors.New(path + " is a directory")
+	}
+	return fileStat{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
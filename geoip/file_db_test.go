@@ -0,0 +1,91 @@
+package geoip
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranges.csv")
+	write := func(data string, mod time.Time) {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func(p string) (IPer, error) { return OpenCSV(p, CSVOptions{}) }
+	now := time.Now()
+
+	write("183.95.0.0,183.95.255.255,中国,湖北省,荆门市,联通\n", now)
+	reloaded := make(chan IPer, 1)
+	failed := make(chan error, 1)
+	db, err := NewFileDB(path, load,
+		WithReloadInterval(10*time.Millisecond),
+		WithSanityIPs("183.95.1.1"),
+		WithOnReload(func(i IPer) { reloaded <- i }),
+		WithOnReloadError(func(err error) { failed <- err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	<-reloaded
+
+	lookupISP := func() string {
+		info, err := db.Lookup(context.Background(), "183.95.1.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.ISP
+	}
+	if isp := lookupISP(); isp != "联通" {
+		t.Fatalf("isp not match, got: %s", isp)
+	}
+
+	// 新版本在后台加载并替换
+	write("183.95.0.0,183.95.255.255,中国,湖北省,荆门市,电信\n", now.Add(time.Second))
+	select {
+	case <-reloaded:
+	case err := <-failed:
+		t.Fatal(err)
+	case <-time.After(time.Second):
+		t.Fatal("reload timeout")
+	}
+	if isp := lookupISP(); isp != "电信" {
+		t.Fatalf("isp not match after reload, got: %s", isp)
+	}
+
+	// 校验失败时保留旧版本
+	write("1.0.0.0,1.0.0.255,Australia,,,\n", now.Add(2*time.Second))
+	select {
+	case i := <-reloaded:
+		t.Fatalf("unexpected reload: %v", i)
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("reload error timeout")
+	}
+	if isp := lookupISP(); isp != "电信" {
+		t.Fatalf("isp not match after failed reload, got: %s", isp)
+	}
+
+	// 文件格式错误时保留旧版本
+	write("not,an,ip\n", now.Add(3*time.Second))
+	if err := db.Reload(); err == nil {
+		t.Fatal("expected reload error")
+	}
+	if isp := lookupISP(); isp != "电信" {
+		t.Fatalf("isp not match after broken file, got: %s", isp)
+	}
+}
+
+func TestNewFileDBError(t *testing.T) {
+	load := func(p string) (IPer, error) { return OpenCSV(p, CSVOptions{}) }
+	if _, err := NewFileDB(filepath.Join(t.TempDir(), "missing.csv"), load); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
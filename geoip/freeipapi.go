@@ -58,14 +58,36 @@ type freeIPapiInfo struct {
 }
 
 func (f *freeIPapiInfo) toInfo() *Info {
-	return &Info{
-		IP:      f.IPAddress,
-		Country: f.CountryName,
-		Region:  f.RegionName,
-		City:    f.CityName,
-		ISP:     f.ASNOrganization,
-		Address: f.RegionName + " " + f.CityName,
+	info := Info{
+		IP:          f.IPAddress,
+		Country:     f.CountryName,
+		CountryCode: f.CountryCode,
+		Region:      f.RegionName,
+		City:        f.CityName,
+		ISP:         f.ASNOrganization,
+		Address:     joinNonEmpty(" ", f.RegionName, f.CityName),
+		Latitude:    f.Latitude,
+		Longitude:   f.Longitude,
+		Postal:      f.ZipCode,
+		ASN:         parseASN(f.ASN),
+		Org:         f.ASNOrganization,
 	}
+	if len(f.TimeZones) > 0 {
+		info.Timezone = f.TimeZones[0]
+	}
+	if f.IsProxy {
+		info.Privacy = &Privacy{Proxy: true}
+	}
+	return &info
+}
+
+func init() {
+	Register(Provider{
+		Name:        "freeipapi",
+		Description: "free.freeipapi.com, results for China may be inaccurate",
+		Languages:   []Language{English},
+		New:         func(Credentials) (IPer, error) { return NewFreeIPAPI(), nil },
+	})
 }
 
 type FreeIPAPI struct{}
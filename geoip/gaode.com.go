@@ -2,7 +2,10 @@ package geoip
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // gaodeInfo 包含高德地图IP定位API返回的IP地理位置信息的完整结构体
@@ -16,46 +19,129 @@ import (
 //		"adcode": "110000",
 //		"rectangle": "116.0119343,39.66127144;116.7829835,40.2164962"
 //	}
+//
+// 国外及局域网 IP 的地址字段返回空数组
+//
+//	{"status":"1","info":"OK","infocode":"10000","province":[],"city":[],"adcode":[],"rectangle":[]}
 type gaodeInfo struct {
-	Status    string `json:"status"`    // 状态值 0:失败; 1:成功
-	Info      string `json:"info"`      // 状态说明 失败原因 or "OK"
-	Infocode  string `json:"infocode"`  // 状态码 10000 表示正确
-	Province  string `json:"province"`  // 省份 非法及国外IP地址是 无数据
-	City      string `json:"city"`      // 城市 非法及国外IP地址是 无数据
-	Adcode    string `json:"adcode"`    // 城市的 adcode
-	Rectangle string `json:"rectangle"` // 左下右上对标对
+	Status    string      `json:"status"`    // 状态值 0:失败; 1:成功
+	Info      string      `json:"info"`      // 状态说明 失败原因 or "OK"
+	Infocode  string      `json:"infocode"`  // 状态码 10000 表示正确
+	Province  gaodeString `json:"province"`  // 省份 非法及国外IP地址是 无数据
+	City      gaodeString `json:"city"`      // 城市 非法及国外IP地址是 无数据
+	Adcode    gaodeString `json:"adcode"`    // 城市的 adcode
+	Rectangle gaodeString `json:"rectangle"` // 左下右上对标对
 }
 
-func (g *gaodeInfo) toInfo() *Info {
-	return &Info{
-		IP:         "",   // 高德API不返回IP地址
-		Country:    "中国", // 高德地图主要针对中国IP
-		Region:     g.Province,
-		RegionCode: "",
-		City:       g.City,
-		CityCode:   g.Adcode,
-		ISP:        "",
-		Address:    g.Province + " " + g.City,
+// gaodeString 高德在无数据时返回 [] 而非字符串，统一解析为字符串
+type gaodeString string
+
+func (s *gaodeString) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '[' {
+		var arr []string
+		if err := json.Unmarshal(b, &arr); err != nil {
+			return err
+		}
+		*s = gaodeString(strings.Join(arr, ""))
+		return nil
 	}
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	*s = gaodeString(str)
+	return nil
+}
+
+func (g *gaodeInfo) toInfo(ip string) *Info {
+	info := Info{
+		IP:       ip,
+		Country:  "中国", // 高德地图主要针对中国IP
+		Region:   string(g.Province),
+		City:     string(g.City),
+		CityCode: string(g.Adcode),
+		ISP:      "",
+		Address:  joinNonEmpty(" ", string(g.Province), string(g.City)),
+	}
+	info.RegionCode, _ = splitAdcode(string(g.Adcode))
+	if b, ok := parseGaodeRectangle(string(g.Rectangle)); ok {
+		info.Bounds = b
+		info.Latitude, info.Longitude = b.Center()
+	}
+	return &info
+}
+
+// parseGaodeRectangle 解析 "左下经度,左下纬度;右上经度,右上纬度" 格式的 GCJ-02 矩形，转换为 WGS-84 坐标
+func parseGaodeRectangle(s string) (*Bounds, bool) {
+	lower, upper, ok := strings.Cut(s, ";")
+	if !ok {
+		return nil, false
+	}
+	minLat, minLng, ok1 := parseLngLat(lower)
+	maxLat, maxLng, ok2 := parseLngLat(upper)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	var b Bounds
+	b.MinLatitude, b.MinLongitude = gcj02ToWGS84(minLat, minLng)
+	b.MaxLatitude, b.MaxLongitude = gcj02ToWGS84(maxLat, maxLng)
+	return &b, true
+}
+
+func parseLngLat(s string) (lat, lng float64, ok bool) {
+	x, y, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	lng, err1 := strconv.ParseFloat(strings.TrimSpace(x), 64)
+	lat, err2 := strconv.ParseFloat(strings.TrimSpace(y), 64)
+	return lat, lng, err1 == nil && err2 == nil
+}
+
+func (g *gaodeInfo) toError(ip string) error {
+	var err error
+	switch g.Infocode {
+	case "20000", "20001", "20003":
+		// INVALID_PARAMS 等参数错误，通常是非法 IP
+		return &NotFoundError{Provider: "gaode", IP: ip, Reason: g.Info}
+	case "10003", "10004", "10014", "10019", "10020", "10021", "10044", "10045":
+		err = ErrQuotaExceeded
+	case "10001", "10002", "10005", "10006", "10007", "10008", "10009", "10010", "10012":
+		err = ErrUnauthorized
+	default:
+		return fmt.Errorf("gaode: %s (infocode: %s)", g.Info, g.Infocode)
+	}
+	return fmt.Errorf("gaode: %s (infocode: %s): %w", g.Info, g.Infocode, err)
+}
+
+func init() {
+	Register(Provider{
+		Name:        "gaode",
+		Description: "高德地图 IP 定位，仅支持国内 IPv4，需要 key",
+		Languages:   []Language{Chinese},
+		Credentials: []Credential{{Name: "key"}},
+		New:         func(c Credentials) (IPer, error) { return NewGaode(c["key"]), nil },
+	})
 }
 
 // Gaode 实现高德地图IP定位API
 type Gaode struct {
-	key string
+	key  string
+	link string
 }
 
 // NewGaode 创建Gaode实例
 func NewGaode(key string) IPer {
 	return &Gaode{
-		key: key,
+		key:  key,
+		link: "https://restapi.amap.com/v3/ip",
 	}
 }
 
 // Lookup 获取IP地理位置信息
+// 国外、局域网及非法 IP 返回 *NotFoundError
 func (g *Gaode) Lookup(ctx context.Context, ip string) (*Info, error) {
-	// 高德地图IP定位API需要key参数，这里使用一个示例key
-	// 实际使用时应该从配置中获取
-	link := fmt.Sprintf("https://restapi.amap.com/v3/ip?key=%s&ip=%s", g.key, ip)
+	link := fmt.Sprintf("%s?key=%s&ip=%s", g.link, g.key, ip)
 	var out gaodeInfo
 	err := request(ctx, link, &out, nil)
 	if err != nil {
@@ -64,8 +150,11 @@ func (g *Gaode) Lookup(ctx context.Context, ip string) (*Info, error) {
 
 	// 检查API响应状态
 	if out.Status != "1" || out.Infocode != "10000" {
-		return nil, fmt.Errorf("高德地图API错误: %s (状态码: %s)", out.Info, out.Infocode)
+		return nil, out.toError(ip)
+	}
+	if out.Province == "" {
+		return nil, &NotFoundError{Provider: "gaode", IP: ip, Reason: "foreign or local ip"}
 	}
 
-	return out.toInfo(), nil
+	return out.toInfo(ip), nil
 }
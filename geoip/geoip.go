@@ -3,11 +3,13 @@ package geoip
 import (
 	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -18,8 +20,14 @@ type (
 )
 
 const (
-	English = Language("en")
-	Chinese = Language("zh-CN")
+	English      = Language("en")
+	Chinese      = Language("zh-CN")
+	German       = Language("de")
+	Spanish      = Language("es")
+	French       = Language("fr")
+	Japanese     = Language("ja")
+	Russian      = Language("ru")
+	PortugueseBR = Language("pt-BR")
 )
 
 type IPer interface {
@@ -32,23 +40,43 @@ type Cacher interface {
 }
 
 type Engine struct {
-	language Language
-	handlers []IPer
-	cache    Cacher
+	language    Language
+	languages   []Language // 本地化名称的回退顺序
+	handlers    []IPer
+	cache       Cacher
+	credentials map[string]Credentials
+	carriers    *CarrierRules    // 自定义运营商规则，优先于内置规则
+	address     *AddressTemplate // 生成 Address 的模板，为空时保留 provider 的 Address
+	enrichers   []Enricher       // 在 process 之后补充信息，如反向解析
+	stats       *engineStats
 }
 
-var defaultEngine atomic.Pointer[Engine]
+var (
+	defaultEngine     atomic.Pointer[Engine]
+	defaultEngineOnce sync.Once
+)
 
 func SetDefault(e *Engine) {
 	defaultEngine.Store(e)
 }
 
-func init() {
-	SetDefault(New(Chinese))
+// Default 返回默认 Engine，未调用 SetDefault 时在首次使用时创建 New(Chinese)
+// 延迟创建可以确保所有 provider 的 init 注册都已完成
+func Default() *Engine {
+	if e := defaultEngine.Load(); e != nil {
+		return e
+	}
+	// 只创建一次，避免并发调用时多余的 Engine 及其缓存协程
+	defaultEngineOnce.Do(func() {
+		if defaultEngine.Load() == nil {
+			defaultEngine.CompareAndSwap(nil, New(Chinese))
+		}
+	})
+	return defaultEngine.Load()
 }
 
 func Lookup(ctx context.Context, ip string) (*Info, error) {
-	return defaultEngine.Load().Lookup(ctx, ip)
+	return Default().Lookup(ctx, ip)
 }
 
 func New(language Language, opts ...Option) *Engine {
@@ -57,73 +85,227 @@ func New(language Language, opts ...Option) *Engine {
 		cache:    NewGeoIPCache(time.Hour),
 	}
 
-	switch language {
-	case English:
-		e.handlers = append(e.handlers, NewIPapi(), NewFreeIPAPI(), NewIfconfigco(), NewIPwho())
-	case Chinese:
-		e.handlers = append(e.handlers, NewWhoisPconline())
-	}
-
 	for _, opt := range opts {
 		opt(&e)
 	}
 
+	if e.handlers == nil {
+		e.handlers = e.defaultHandlers()
+	}
+	e.stats = newEngineStats(e.handlers)
 	return &e
 }
 
+// keyedChineseProviders 配置了凭据时追加到中文默认链路的 provider
+var keyedChineseProviders = []string{"gaode", "baidu", "tencent"}
+
+// hasDefaultHandlers 语言是否有默认 provider
+func hasDefaultHandlers(language Language) bool {
+	return language == English || language == Chinese
+}
+
+// defaultHandlers 返回语言对应的默认 provider
+// 中文在 pconline 之后追加已配置凭据(WithCredentials 或环境变量)的 gaode/baidu/tencent
+func (e *Engine) defaultHandlers() []IPer {
+	switch e.language {
+	case English:
+		ipapi := NewIPapi()
+		if len(e.languages) > 0 && e.languages[0] != English && slices.Contains(ipapiLanguages, e.languages[0]) {
+			ipapi = NewIPapiWithLanguage(e.languages[0])
+		}
+		return []IPer{ipapi, NewFreeIPAPI(), NewIfconfigco(), NewIPwho()}
+	case Chinese:
+		handlers := []IPer{NewWhoisPconline()}
+		for _, name := range keyedChineseProviders {
+			p, ok := GetProvider(name)
+			if !ok {
+				continue
+			}
+			if iper, err := NewProvider(name, credentialsFromEnv(p, e.credentials[name])); err == nil {
+				handlers = append(handlers, iper)
+			}
+		}
+		return handlers
+	}
+	return []IPer{}
+}
+
+// Cache 返回 Engine 使用的缓存
+func (e *Engine) Cache() Cacher {
+	return e.cache
+}
+
 func (e *Engine) Lookup(ctx context.Context, ip string) (info *Info, err error) {
 	netip := net.ParseIP(ip)
 	if netip == nil {
-		return nil, errors.New("invalid ip")
+		return nil, ErrInvalidIP
 	}
 	if netip.IsPrivate() {
 		return nil, ErrPrivateIP
 	}
 
+	e.stats.lookups.Add(1)
 	if e.cache != nil {
 		info, err = e.cache.Get(ip)
 		if err == nil {
+			e.stats.cacheHits.Add(1)
 			return
 		}
 	}
 
-	for _, handler := range e.handlers {
-		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
-		info, err = handler.Lookup(ctx, ip)
+	for i, handler := range e.handlers {
+		hctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		info, err = handler.Lookup(hctx, ip)
 		cancel()
+		e.stats.providers[i].record(err)
 		if err == nil {
+			e.process(info)
+			for _, en := range e.enrichers {
+				en.Enrich(ctx, ip, info)
+			}
 			if e.cache != nil {
 				e.cache.Set(ip, info)
 			}
 			return
 		}
 	}
+	e.stats.failures.Add(1)
+	if len(e.handlers) == 0 {
+		return nil, ErrNoProviders
+	}
 	return info, err
 }
 
+// process 对 provider 返回的结果进行加工，在写入缓存之前执行
+func (e *Engine) process(info *Info) {
+	info.Normalize(e.language)
+	info.fillAdcode()
+	lang := e.language
+	if len(e.languages) > 0 {
+		lang = e.languages[0]
+		info.Localize(e.languages...)
+	}
+	info.fillCarrier(e.carriers, lang)
+	if e.address != nil {
+		info.Address = e.address.Format(info)
+	}
+}
+
 type Info struct {
-	IP         string
-	Country    string // Country
-	Region     string // Province/State
-	RegionCode string // Province/State code
-	City       string // City
-	CityCode   string // City code
-	ISP        string // Internet Service Provider
-	Address    string // Address (e.g., "Hubei Province Jingmen City China Unicom")
+	IP               string
+	Country          string   // Country
+	CountryCode      string   // ISO 3166-1 alpha-2 country code
+	CountryAlpha3    string   // ISO 3166-1 alpha-3 country code, see Normalize
+	CountryNumeric   string   // ISO 3166-1 numeric country code, see Normalize
+	SubdivisionCode  string   // ISO 3166-2 code of the region (e.g., "CN-HB"), see Normalize
+	Region           string   // Province/State
+	RegionCode       string   // Province/State code, 6-digit adcode for China (e.g., "420000")
+	City             string   // City
+	CityCode         string   // City code, 6-digit adcode for China (e.g., "420800")
+	ISP              string   // Internet Service Provider
+	Address          string   // Address (e.g., "Hubei Province Jingmen City China Unicom")
+	Latitude         float64  // Latitude
+	Longitude        float64  // Longitude
+	AccuracyRadius   int      // Accuracy radius of the coordinates in kilometers, 0 if unknown
+	Postal           string   // Postal code
+	Timezone         string   // IANA time zone (e.g., "Asia/Shanghai")
+	ASN              int      // Autonomous system number
+	Org              string   // Organization that owns the network
+	Prefix           string   // Announced network prefix in CIDR notation (e.g., "8.8.8.0/24")
+	Registry         string   // Regional Internet registry of the prefix (e.g., "arin", "apnic")
+	Hostname         string   // Reverse DNS (PTR) hostname, see NewReverseDNS
+	HostnameVerified bool     // Hostname resolves back to the IP (forward-confirmed reverse DNS)
+	Tags             []string // Network tags set by enrichers (e.g., "cloud", "provider:aws", "region:us-east-1")
+	Carrier          *Carrier // Canonical carrier resolved from ASN/ISP/Org, nil if not recognized
+	Privacy          *Privacy // Anonymity detection, nil if the provider does not support it
+	Company          *Company // Company that uses the IP, nil if the provider does not support it
+	Abuse            *Abuse   // Abuse contact, nil if the provider does not support it
+	Bounds           *Bounds  // Bounding box of the located area, nil if the provider does not support it
+
+	Localized map[Language]Names // Country/Region/City names per language, see WithLanguages
+}
+
+// HasTag reports whether the info has the tag, see Info.Tags
+func (i *Info) HasTag(tag string) bool {
+	return i != nil && slices.Contains(i.Tags, tag)
+}
+
+// AddTags appends tags that are not present yet
+func (i *Info) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if tag != "" && !i.HasTag(tag) {
+			i.Tags = append(i.Tags, tag)
+		}
+	}
+}
+
+// Bounds bounding box in WGS-84 coordinates
+type Bounds struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Center returns the centroid of the bounding box
+func (b *Bounds) Center() (lat, lon float64) {
+	return (b.MinLatitude + b.MaxLatitude) / 2, (b.MinLongitude + b.MaxLongitude) / 2
+}
+
+// Privacy anonymity detection of an IP
+type Privacy struct {
+	VPN     bool   // VPN exit node
+	Proxy   bool   // Open or web proxy
+	Tor     bool   // Tor exit node
+	Relay   bool   // Anonymous relay, e.g. iCloud Private Relay
+	Hosting bool   // Hosting or cloud provider
+	Service string // Name of the VPN/proxy service if known
+}
+
+// Company company that uses the IP
+type Company struct {
+	Name   string
+	Domain string
+	Type   string // isp, business, education, hosting
+}
+
+// Abuse abuse contact of the network
+type Abuse struct {
+	Name    string
+	Email   string
+	Phone   string
+	Address string
+	Country string
+	Network string // CIDR the contact is responsible for
 }
 
 func request(ctx context.Context, link string, out any, wrapBody WrapBodyHandler) error {
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	return do(req, out, wrapBody)
+}
+
+// do 发送请求并解析 JSON 响应，非 200 响应返回 *StatusError
+func do(req *http.Request, out any, wrapBody WrapBodyHandler) error {
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("status code: %d", resp.StatusCode)
+		return NewStatusError(resp)
 	}
 	if wrapBody != nil {
 		return json.NewDecoder(wrapBody(resp.Body)).Decode(&out)
 	}
 	return json.NewDecoder(resp.Body).Decode(&out)
 }
+
+// parseASN 解析 "AS4837"、"4837"、"AS199524 G-Core Labs S.A." 等格式的 ASN
+func parseASN(s string) int {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), " ")
+	if len(s) > 2 && strings.EqualFold(s[:2], "AS") {
+		s = s[2:]
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
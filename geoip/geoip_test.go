@@ -2,6 +2,8 @@ package geoip
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 )
 
@@ -151,3 +153,28 @@ func TestLookupIPWithIPapi(t *testing.T) {
 		t.Fatalf("address not match, got: %s, expected: %s", info.Address, expectedAddr)
 	}
 }
+
+func TestDefault(t *testing.T) {
+	var wg sync.WaitGroup
+	engines := make([]*Engine, 8)
+	for i := range engines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			engines[i] = Default()
+		}()
+	}
+	wg.Wait()
+	for _, e := range engines {
+		if e == nil || e != engines[0] {
+			t.Fatal("expected the same default engine")
+		}
+	}
+}
+
+func TestLookupNoProviders(t *testing.T) {
+	info, err := New("ja").Lookup(context.Background(), "8.8.8.8")
+	if info != nil || !errors.Is(err, ErrNoProviders) {
+		t.Fatalf("expected ErrNoProviders, got: %v %v", info, err)
+	}
+}
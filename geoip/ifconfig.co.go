@@ -54,17 +54,32 @@ type ifconfigcoInfo struct {
 
 func (i *ifconfigcoInfo) toInfo() *Info {
 	return &Info{
-		IP:         i.IP,
-		Country:    i.Country,
-		Region:     i.RegionName,
-		RegionCode: i.RegionCode,
-		City:       i.City,
-		CityCode:   "", // ifconfig.co API does not provide city code
-		ISP:        i.ASNOrg,
-		Address:    i.Country + " " + i.RegionName + " " + i.City + " " + i.ASNOrg,
+		IP:          i.IP,
+		Country:     i.Country,
+		CountryCode: i.CountryISO,
+		Region:      i.RegionName,
+		RegionCode:  i.RegionCode,
+		City:        i.City,
+		CityCode:    "", // ifconfig.co API does not provide city code
+		ISP:         i.ASNOrg,
+		Address:     joinNonEmpty(" ", i.Country, i.RegionName, i.City, i.ASNOrg),
+		Latitude:    i.Latitude,
+		Longitude:   i.Longitude,
+		Timezone:    i.TimeZone,
+		ASN:         parseASN(i.ASN),
+		Org:         i.ASNOrg,
 	}
 }
 
+func init() {
+	Register(Provider{
+		Name:        "ifconfigco",
+		Description: "ifconfig.co",
+		Languages:   []Language{English},
+		New:         func(Credentials) (IPer, error) { return NewIfconfigco(), nil },
+	})
+}
+
 // Ifconfigco implements IPer interface
 type Ifconfigco struct{}
 
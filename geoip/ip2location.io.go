@@ -0,0 +1,160 @@
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ip2locationInfo contains the IP geolocation information struct returned by ip2location.io API
+// isp/domain/proxy are only returned by paid plans
+//
+//	{
+//	    "ip": "8.8.8.8",
+//	    "country_code": "US",
+//	    "country_name": "United States of America",
+//	    "region_name": "California",
+//	    "city_name": "Mountain View",
+//	    "latitude": 37.38605,
+//	    "longitude": -122.08385,
+//	    "zip_code": "94035",
+//	    "time_zone": "-07:00",
+//	    "asn": "15169",
+//	    "as": "Google LLC",
+//	    "isp": "Google LLC",
+//	    "domain": "google.com",
+//	    "is_proxy": false
+//	}
+type ip2locationInfo struct {
+	IP          string            `json:"ip"`           // IP address
+	CountryCode string            `json:"country_code"` // Country code
+	CountryName string            `json:"country_name"` // Country name
+	RegionName  string            `json:"region_name"`  // Region name
+	CityName    string            `json:"city_name"`    // City name
+	Latitude    float64           `json:"latitude"`     // Latitude
+	Longitude   float64           `json:"longitude"`    // Longitude
+	ZipCode     string            `json:"zip_code"`     // Postal code
+	TimeZone    string            `json:"time_zone"`    // UTC offset, e.g. "-07:00"
+	ASN         string            `json:"asn"`          // ASN number
+	AS          string            `json:"as"`           // ASN name
+	ISP         string            `json:"isp"`          // Internet Service Provider
+	Domain      string            `json:"domain"`       // Domain
+	IsProxy     bool              `json:"is_proxy"`     // Whether it's a proxy
+	Proxy       *ip2locationProxy `json:"proxy"`        // Proxy detail
+	Error       *ip2locationErr   `json:"error"`        // Error detail
+}
+
+// ip2locationProxy proxy detail struct
+type ip2locationProxy struct {
+	ProxyType          string `json:"proxy_type"`           // VPN, TOR, DCH, PUB, WEB, SES, RES
+	IsVPN              bool   `json:"is_vpn"`               // VPN
+	IsTor              bool   `json:"is_tor"`               // Tor exit node
+	IsDataCenter       bool   `json:"is_data_center"`       // Datacenter
+	IsPublicProxy      bool   `json:"is_public_proxy"`      // Public proxy
+	IsWebProxy         bool   `json:"is_web_proxy"`         // Web proxy
+	IsResidentialProxy bool   `json:"is_residential_proxy"` // Residential proxy
+	Provider           string `json:"provider"`             // VPN provider
+}
+
+// ip2locationErr error detail struct
+//
+//	{"error": {"error_code": 10000, "error_message": "Invalid API key or insufficient credit."}}
+type ip2locationErr struct {
+	Code    int    `json:"error_code"`    // Error code
+	Message string `json:"error_message"` // Error message
+}
+
+func (i *ip2locationInfo) toInfo() *Info {
+	info := Info{
+		IP:          i.IP,
+		Country:     i.CountryName,
+		CountryCode: i.CountryCode,
+		Region:      i.RegionName,
+		City:        i.CityName,
+		ISP:         i.ISP,
+		Address:     joinNonEmpty(" ", i.CountryName, i.RegionName, i.CityName, i.AS),
+		Latitude:    i.Latitude,
+		Longitude:   i.Longitude,
+		Postal:      i.ZipCode,
+		Org:         i.AS,
+	}
+	info.ASN, _ = strconv.Atoi(i.ASN)
+	if info.ISP == "" {
+		info.ISP = i.AS
+	}
+	if i.Proxy != nil {
+		info.Privacy = &Privacy{
+			VPN:     i.Proxy.IsVPN,
+			Proxy:   i.Proxy.IsPublicProxy || i.Proxy.IsWebProxy || i.Proxy.IsResidentialProxy,
+			Tor:     i.Proxy.IsTor,
+			Hosting: i.Proxy.IsDataCenter,
+			Service: i.Proxy.Provider,
+		}
+	} else if i.IsProxy {
+		info.Privacy = &Privacy{Proxy: true}
+	}
+	return &info
+}
+
+// toError 按错误码归类，无法识别的错误码保留状态码的归类，se 为 nil 表示 200 响应中的错误
+func (e *ip2locationErr) toError(se *StatusError) error {
+	var err error
+	switch e.Code {
+	case 10000:
+		err = ErrUnauthorized
+	case 10001:
+		err = ErrNotFound
+	default:
+		if se != nil {
+			return fmt.Errorf("ip2location.io error %d: %s: %w", e.Code, e.Message, se)
+		}
+		return fmt.Errorf("ip2location.io error %d: %s", e.Code, e.Message)
+	}
+	return fmt.Errorf("ip2location.io error %d: %s: %w", e.Code, e.Message, err)
+}
+
+func init() {
+	Register(Provider{
+		Name:        "ip2location",
+		Description: "ip2location.io, requires key",
+		Languages:   []Language{English},
+		Credentials: []Credential{{Name: "key"}},
+		New:         func(c Credentials) (IPer, error) { return NewIP2Location(c["key"]), nil },
+	})
+}
+
+// IP2Location implements IPer interface for ip2location.io
+type IP2Location struct {
+	key  string
+	link string
+}
+
+// NewIP2Location creates IP2Location instance
+func NewIP2Location(key string) IPer {
+	return &IP2Location{
+		key:  key,
+		link: "https://api.ip2location.io/",
+	}
+}
+
+// Lookup retrieves IP geolocation information
+func (i *IP2Location) Lookup(ctx context.Context, ip string) (*Info, error) {
+	q := url.Values{"key": {i.key}, "ip": {ip}, "format": {"json"}}
+	var out ip2locationInfo
+	err := request(ctx, i.link+"?"+q.Encode(), &out, nil)
+	if err != nil {
+		// error responses carry the error object along with a non-200 status
+		var se *StatusError
+		if errors.As(err, &se) && json.Unmarshal(se.Body, &out) == nil && out.Error != nil {
+			return nil, out.Error.toError(se)
+		}
+		return nil, err
+	}
+	if out.Error != nil {
+		return nil, out.Error.toError(nil)
+	}
+	return out.toInfo(), nil
+}
@@ -40,37 +40,72 @@ type ipapiInfo struct {
 	Query       string  `json:"query"`       // Queried IP address
 }
 
-func (i *ipapiInfo) toInfo() *Info {
-	return &Info{
-		IP:         i.Query,
-		Country:    i.Country,
-		Region:     i.RegionName,
-		RegionCode: i.Region,
-		City:       i.City,
-		CityCode:   "",
-		ISP:        i.ISP,
-		Address:    i.Country + " " + i.RegionName + " " + i.City + " " + i.Org,
+func (i *ipapiInfo) toInfo(lang Language) *Info {
+	info := Info{
+		IP:          i.Query,
+		Country:     i.Country,
+		CountryCode: i.CountryCode,
+		Region:      i.RegionName,
+		RegionCode:  i.Region,
+		City:        i.City,
+		CityCode:    "",
+		ISP:         i.ISP,
+		Address:     joinNonEmpty(" ", i.Country, i.RegionName, i.City, i.Org),
+		Latitude:    i.Lat,
+		Longitude:   i.Lon,
+		Postal:      i.Zip,
+		Timezone:    i.Timezone,
+		ASN:         parseASN(i.AS),
+		Org:         i.Org,
 	}
+	if lang != "" {
+		info.Localized = map[Language]Names{lang: {Country: i.Country, Region: i.RegionName, City: i.City}}
+	}
+	return &info
+}
+
+func init() {
+	Register(Provider{
+		Name:        "ipapi",
+		Description: "ip-api.com free endpoint, 45 requests per minute",
+		Languages:   []Language{English},
+		New:         func(Credentials) (IPer, error) { return NewIPapi(), nil },
+	})
 }
 
+// ipapiLanguages languages supported by the lang parameter of ip-api.com
+var ipapiLanguages = []Language{English, German, Spanish, PortugueseBR, French, Japanese, Chinese, Russian}
+
 // IPapi implements IPer interface
-type IPapi struct{}
+type IPapi struct {
+	lang Language
+}
 
 // NewIPapi creates IPapi instance
 func NewIPapi() IPer {
 	return &IPapi{}
 }
 
+// NewIPapiWithLanguage creates IPapi instance that returns names in lang
+// Supported languages: en, de, es, pt-BR, fr, ja, zh-CN, ru
+func NewIPapiWithLanguage(lang Language) IPer {
+	return &IPapi{lang: lang}
+}
+
 // Lookup retrieves IP geolocation information
 func (i *IPapi) Lookup(ctx context.Context, ip string) (*Info, error) {
 	const link = "http://ip-api.com/json/"
 	var out ipapiInfo
-	err := request(ctx, link+ip, &out, nil)
+	query := ""
+	if i.lang != "" && i.lang != English {
+		query = "?lang=" + string(i.lang)
+	}
+	err := request(ctx, link+ip+query, &out, nil)
 	if err != nil {
 		return nil, err
 	}
 	if out.Status != "success" {
 		return nil, errors.New("API request failed with status: " + out.Status)
 	}
-	return out.toInfo(), nil
+	return out.toInfo(i.lang), nil
 }
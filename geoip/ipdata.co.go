@@ -0,0 +1,162 @@
+package geoip
+
+import (
+	"context"
+	"net/url"
+)
+
+// ipdataInfo contains the IP geolocation information struct returned by ipdata.co API
+//
+//	{
+//	    "ip": "8.8.8.8",
+//	    "is_eu": false,
+//	    "city": "Mountain View",
+//	    "region": "California",
+//	    "region_code": "CA",
+//	    "region_type": "state",
+//	    "country_name": "United States",
+//	    "country_code": "US",
+//	    "continent_name": "North America",
+//	    "continent_code": "NA",
+//	    "latitude": 37.386,
+//	    "longitude": -122.0838,
+//	    "postal": "94035",
+//	    "calling_code": "1",
+//	    "asn": {
+//	        "asn": "AS15169",
+//	        "name": "Google LLC",
+//	        "domain": "google.com",
+//	        "route": "8.8.8.0/24",
+//	        "type": "business"
+//	    },
+//	    "time_zone": {
+//	        "name": "America/Los_Angeles",
+//	        "abbr": "PST",
+//	        "offset": "-0800",
+//	        "is_dst": false
+//	    },
+//	    "threat": {
+//	        "is_tor": false,
+//	        "is_icloud_relay": false,
+//	        "is_proxy": false,
+//	        "is_datacenter": true,
+//	        "is_anonymous": false,
+//	        "is_known_attacker": false,
+//	        "is_known_abuser": false,
+//	        "is_threat": false,
+//	        "is_bogon": false
+//	    }
+//	}
+type ipdataInfo struct {
+	IP            string         `json:"ip"`             // IP address
+	IsEU          bool           `json:"is_eu"`          // Whether it's EU country
+	City          string         `json:"city"`           // City name
+	Region        string         `json:"region"`         // Region name
+	RegionCode    string         `json:"region_code"`    // Region code
+	RegionType    string         `json:"region_type"`    // Region type
+	CountryName   string         `json:"country_name"`   // Country name
+	CountryCode   string         `json:"country_code"`   // Country code
+	ContinentName string         `json:"continent_name"` // Continent name
+	ContinentCode string         `json:"continent_code"` // Continent code
+	Latitude      float64        `json:"latitude"`       // Latitude
+	Longitude     float64        `json:"longitude"`      // Longitude
+	Postal        string         `json:"postal"`         // Postal code
+	CallingCode   string         `json:"calling_code"`   // Calling code
+	ASN           ipdataASN      `json:"asn"`            // ASN information
+	TimeZone      ipdataTimeZone `json:"time_zone"`      // Time zone information
+	Threat        ipdataThreat   `json:"threat"`         // Threat intelligence
+}
+
+// ipdataASN ASN information struct
+type ipdataASN struct {
+	ASN    string `json:"asn"`    // ASN number
+	Name   string `json:"name"`   // ASN name
+	Domain string `json:"domain"` // ASN domain
+	Route  string `json:"route"`  // Announced route
+	Type   string `json:"type"`   // isp, business, education, hosting
+}
+
+// ipdataTimeZone time zone information struct
+type ipdataTimeZone struct {
+	Name   string `json:"name"`   // IANA time zone
+	Abbr   string `json:"abbr"`   // Abbreviation
+	Offset string `json:"offset"` // UTC offset
+	IsDST  bool   `json:"is_dst"` // Whether it's DST
+}
+
+// ipdataThreat threat intelligence struct
+type ipdataThreat struct {
+	IsTor           bool `json:"is_tor"`            // Tor exit node
+	IsICloudRelay   bool `json:"is_icloud_relay"`   // iCloud Private Relay
+	IsProxy         bool `json:"is_proxy"`          // Proxy
+	IsDatacenter    bool `json:"is_datacenter"`     // Datacenter
+	IsAnonymous     bool `json:"is_anonymous"`      // Anonymous network
+	IsKnownAttacker bool `json:"is_known_attacker"` // Known attacker
+	IsKnownAbuser   bool `json:"is_known_abuser"`   // Known abuser
+	IsThreat        bool `json:"is_threat"`         // Threat
+	IsBogon         bool `json:"is_bogon"`          // Bogon address
+}
+
+func (i *ipdataInfo) toInfo() *Info {
+	return &Info{
+		IP:          i.IP,
+		Country:     i.CountryName,
+		CountryCode: i.CountryCode,
+		Region:      i.Region,
+		RegionCode:  i.RegionCode,
+		City:        i.City,
+		ISP:         i.ASN.Name,
+		Address:     joinNonEmpty(" ", i.CountryName, i.Region, i.City, i.ASN.Name),
+		Latitude:    i.Latitude,
+		Longitude:   i.Longitude,
+		Postal:      i.Postal,
+		Timezone:    i.TimeZone.Name,
+		ASN:         parseASN(i.ASN.ASN),
+		Org:         i.ASN.Name,
+		Privacy: &Privacy{
+			VPN:     i.Threat.IsAnonymous && !i.Threat.IsTor && !i.Threat.IsProxy,
+			Proxy:   i.Threat.IsProxy,
+			Tor:     i.Threat.IsTor,
+			Relay:   i.Threat.IsICloudRelay,
+			Hosting: i.Threat.IsDatacenter,
+		},
+	}
+}
+
+func init() {
+	Register(Provider{
+		Name:        "ipdata",
+		Description: "ipdata.co, requires api-key",
+		Languages:   []Language{English},
+		Credentials: []Credential{{Name: "key"}},
+		New:         func(c Credentials) (IPer, error) { return NewIPdata(c["key"]), nil },
+	})
+}
+
+// IPdata implements IPer interface for ipdata.co
+type IPdata struct {
+	key  string
+	link string
+}
+
+// NewIPdata creates IPdata instance
+func NewIPdata(key string) IPer {
+	return &IPdata{
+		key:  key,
+		link: "https://api.ipdata.co/",
+	}
+}
+
+// Lookup retrieves IP geolocation information
+func (i *IPdata) Lookup(ctx context.Context, ip string) (*Info, error) {
+	q := url.Values{"api-key": {i.key}}
+	var out ipdataInfo
+	err := request(ctx, i.link+ip+"?"+q.Encode(), &out, nil)
+	if err != nil {
+		return nil, err
+	}
+	if out.Threat.IsBogon {
+		return nil, ErrPrivateIP
+	}
+	return out.toInfo(), nil
+}
@@ -0,0 +1,128 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ipgeolocationInfo contains the IP geolocation information struct returned by ipgeolocation.io API
+//
+//	{
+//	    "ip": "8.8.8.8",
+//	    "continent_code": "NA",
+//	    "continent_name": "North America",
+//	    "country_code2": "US",
+//	    "country_code3": "USA",
+//	    "country_name": "United States",
+//	    "country_name_official": "United States of America",
+//	    "state_prov": "California",
+//	    "state_code": "US-CA",
+//	    "district": "Santa Clara",
+//	    "city": "Mountain View",
+//	    "zipcode": "94043-1351",
+//	    "latitude": "37.42240",
+//	    "longitude": "-122.08421",
+//	    "is_eu": false,
+//	    "calling_code": "+1",
+//	    "isp": "Google LLC",
+//	    "connection_type": "",
+//	    "organization": "Google LLC",
+//	    "asn": "AS15169",
+//	    "time_zone": {
+//	        "name": "America/Los_Angeles",
+//	        "offset": -8,
+//	        "is_dst": false
+//	    }
+//	}
+type ipgeolocationInfo struct {
+	IP             string                `json:"ip"`              // IP address
+	ContinentCode  string                `json:"continent_code"`  // Continent code
+	ContinentName  string                `json:"continent_name"`  // Continent name
+	CountryCode2   string                `json:"country_code2"`   // ISO 3166-1 alpha-2 code
+	CountryCode3   string                `json:"country_code3"`   // ISO 3166-1 alpha-3 code
+	CountryName    string                `json:"country_name"`    // Country name
+	StateProv      string                `json:"state_prov"`      // State or province
+	StateCode      string                `json:"state_code"`      // ISO 3166-2 code, e.g. "US-CA"
+	District       string                `json:"district"`        // District
+	City           string                `json:"city"`            // City name
+	Zipcode        string                `json:"zipcode"`         // Postal code
+	Latitude       string                `json:"latitude"`        // Latitude
+	Longitude      string                `json:"longitude"`       // Longitude
+	IsEU           bool                  `json:"is_eu"`           // Whether it's EU country
+	ISP            string                `json:"isp"`             // Internet Service Provider
+	ConnectionType string                `json:"connection_type"` // Connection type
+	Organization   string                `json:"organization"`    // Organization name
+	ASN            string                `json:"asn"`             // ASN number
+	TimeZone       ipgeolocationTimeZone `json:"time_zone"`       // Time zone information
+}
+
+// ipgeolocationTimeZone time zone information struct
+type ipgeolocationTimeZone struct {
+	Name   string  `json:"name"`   // IANA time zone
+	Offset float64 `json:"offset"` // UTC offset in hours
+	IsDST  bool    `json:"is_dst"` // Whether it's DST
+}
+
+func (i *ipgeolocationInfo) toInfo() *Info {
+	info := Info{
+		IP:          i.IP,
+		Country:     i.CountryName,
+		CountryCode: i.CountryCode2,
+		Region:      i.StateProv,
+		RegionCode:  strings.TrimPrefix(i.StateCode, i.CountryCode2+"-"),
+		City:        i.City,
+		ISP:         i.ISP,
+		Address:     joinNonEmpty(" ", i.CountryName, i.StateProv, i.City, i.Organization),
+		Postal:      i.Zipcode,
+		Timezone:    i.TimeZone.Name,
+		ASN:         parseASN(i.ASN),
+		Org:         i.Organization,
+	}
+	info.Latitude, _ = strconv.ParseFloat(i.Latitude, 64)
+	info.Longitude, _ = strconv.ParseFloat(i.Longitude, 64)
+	return &info
+}
+
+func init() {
+	Register(Provider{
+		Name:        "ipgeolocation",
+		Description: "ipgeolocation.io, requires apiKey",
+		Languages:   []Language{English},
+		Credentials: []Credential{{Name: "key"}},
+		New:         func(c Credentials) (IPer, error) { return NewIPGeolocation(c["key"]), nil },
+	})
+}
+
+// IPGeolocation implements IPer interface for ipgeolocation.io
+type IPGeolocation struct {
+	key  string
+	link string
+}
+
+// NewIPGeolocation creates IPGeolocation instance
+func NewIPGeolocation(key string) IPer {
+	return &IPGeolocation{
+		key:  key,
+		link: "https://api.ipgeolocation.io/ipgeo",
+	}
+}
+
+// Lookup retrieves IP geolocation information
+func (i *IPGeolocation) Lookup(ctx context.Context, ip string) (*Info, error) {
+	q := url.Values{"apiKey": {i.key}, "ip": {ip}}
+	var out ipgeolocationInfo
+	err := request(ctx, i.link+"?"+q.Encode(), &out, nil)
+	if err != nil {
+		// ipgeolocation.io responds 423 Locked for bogon addresses
+		var se *StatusError
+		if errors.As(err, &se) && se.StatusCode == http.StatusLocked {
+			return nil, ErrPrivateIP
+		}
+		return nil, err
+	}
+	return out.toInfo(), nil
+}
@@ -0,0 +1,202 @@
+package geoip
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ipinfoInfo contains the IP geolocation information struct returned by ipinfo.io API
+// The asn/company/privacy/abuse sections are only returned by paid plans
+//
+//	{
+//	    "ip": "8.8.8.8",
+//	    "hostname": "dns.google",
+//	    "city": "Mountain View",
+//	    "region": "California",
+//	    "country": "US",
+//	    "loc": "37.4056,-122.0775",
+//	    "org": "AS15169 Google LLC",
+//	    "postal": "94043",
+//	    "timezone": "America/Los_Angeles",
+//	    "asn": {
+//	        "asn": "AS15169",
+//	        "name": "Google LLC",
+//	        "domain": "google.com",
+//	        "route": "8.8.8.0/24",
+//	        "type": "hosting"
+//	    },
+//	    "company": {
+//	        "name": "Google LLC",
+//	        "domain": "google.com",
+//	        "type": "hosting"
+//	    },
+//	    "privacy": {
+//	        "vpn": false,
+//	        "proxy": false,
+//	        "tor": false,
+//	        "relay": false,
+//	        "hosting": true,
+//	        "service": ""
+//	    },
+//	    "abuse": {
+//	        "address": "US, CA, Mountain View, 1600 Amphitheatre Parkway, 94043",
+//	        "country": "US",
+//	        "email": "network-abuse@google.com",
+//	        "name": "Abuse",
+//	        "network": "8.8.8.0/24",
+//	        "phone": "+1-650-253-0000"
+//	    }
+//	}
+type ipinfoInfo struct {
+	IP       string         `json:"ip"`       // IP address
+	Hostname string         `json:"hostname"` // Reverse DNS hostname
+	Bogon    bool           `json:"bogon"`    // Whether the IP is a bogon (private/reserved) address
+	City     string         `json:"city"`     // City name
+	Region   string         `json:"region"`   // Region name
+	Country  string         `json:"country"`  // Country code
+	Loc      string         `json:"loc"`      // "latitude,longitude"
+	Org      string         `json:"org"`      // ASN and organization name
+	Postal   string         `json:"postal"`   // Postal code
+	Timezone string         `json:"timezone"` // Time zone
+	ASN      *ipinfoASN     `json:"asn"`      // ASN information
+	Company  *ipinfoCompany `json:"company"`  // Company information
+	Privacy  *ipinfoPrivacy `json:"privacy"`  // Privacy detection
+	Abuse    *ipinfoAbuse   `json:"abuse"`    // Abuse contact
+}
+
+// ipinfoASN ASN information struct
+type ipinfoASN struct {
+	ASN    string `json:"asn"`    // ASN number, e.g. "AS15169"
+	Name   string `json:"name"`   // ASN name
+	Domain string `json:"domain"` // ASN domain
+	Route  string `json:"route"`  // Announced route
+	Type   string `json:"type"`   // isp, business, education, hosting
+}
+
+// ipinfoCompany company information struct
+type ipinfoCompany struct {
+	Name   string `json:"name"`   // Company name
+	Domain string `json:"domain"` // Company domain
+	Type   string `json:"type"`   // isp, business, education, hosting
+}
+
+// ipinfoPrivacy privacy detection struct
+type ipinfoPrivacy struct {
+	VPN     bool   `json:"vpn"`     // VPN
+	Proxy   bool   `json:"proxy"`   // Proxy
+	Tor     bool   `json:"tor"`     // Tor exit node
+	Relay   bool   `json:"relay"`   // Anonymous relay
+	Hosting bool   `json:"hosting"` // Hosting provider
+	Service string `json:"service"` // VPN service name
+}
+
+// ipinfoAbuse abuse contact struct
+type ipinfoAbuse struct {
+	Address string `json:"address"` // Address
+	Country string `json:"country"` // Country code
+	Email   string `json:"email"`   // Email
+	Name    string `json:"name"`    // Name
+	Network string `json:"network"` // Network CIDR
+	Phone   string `json:"phone"`   // Phone
+}
+
+func (i *ipinfoInfo) toInfo() *Info {
+	info := Info{
+		IP:          i.IP,
+		CountryCode: i.Country,
+		Region:      i.Region,
+		City:        i.City,
+		Postal:      i.Postal,
+		Timezone:    i.Timezone,
+	}
+	if lat, lon, ok := strings.Cut(i.Loc, ","); ok {
+		info.Latitude, _ = strconv.ParseFloat(lat, 64)
+		info.Longitude, _ = strconv.ParseFloat(lon, 64)
+	}
+
+	// org is "AS15169 Google LLC" when the asn section is not included in the plan
+	asn, org, _ := strings.Cut(i.Org, " ")
+	info.ASN, info.Org = parseASN(asn), org
+	if i.ASN != nil {
+		info.ASN, info.Org = parseASN(i.ASN.ASN), i.ASN.Name
+		info.Prefix = i.ASN.Route
+	}
+	info.ISP = info.Org
+
+	if i.Company != nil {
+		info.Company = &Company{Name: i.Company.Name, Domain: i.Company.Domain, Type: i.Company.Type}
+		if i.Company.Type == "isp" {
+			info.ISP = i.Company.Name
+		}
+	}
+	if i.Privacy != nil {
+		info.Privacy = &Privacy{
+			VPN:     i.Privacy.VPN,
+			Proxy:   i.Privacy.Proxy,
+			Tor:     i.Privacy.Tor,
+			Relay:   i.Privacy.Relay,
+			Hosting: i.Privacy.Hosting,
+			Service: i.Privacy.Service,
+		}
+	}
+	if i.Abuse != nil {
+		info.Abuse = &Abuse{
+			Name:    i.Abuse.Name,
+			Email:   i.Abuse.Email,
+			Phone:   i.Abuse.Phone,
+			Address: i.Abuse.Address,
+			Country: i.Abuse.Country,
+			Network: i.Abuse.Network,
+		}
+	}
+	info.Address = joinNonEmpty(" ", i.City, i.Region, i.Country, info.Org)
+	return &info
+}
+
+func init() {
+	Register(Provider{
+		Name:        "ipinfo",
+		Description: "ipinfo.io, token is optional for the free tier",
+		Languages:   []Language{English},
+		Credentials: []Credential{{Name: "token", Optional: true}},
+		New:         func(c Credentials) (IPer, error) { return NewIPinfo(c["token"]), nil },
+	})
+}
+
+// IPinfo implements IPer interface for ipinfo.io
+type IPinfo struct {
+	token string
+	link  string
+}
+
+// NewIPinfo creates IPinfo instance, token can be empty for the free tier
+func NewIPinfo(token string) IPer {
+	return &IPinfo{
+		token: token,
+		link:  "https://ipinfo.io/",
+	}
+}
+
+// Lookup retrieves IP geolocation information
+// A 429 response is returned as *StatusError wrapping ErrQuotaExceeded
+func (i *IPinfo) Lookup(ctx context.Context, ip string) (*Info, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.link+ip+"/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if i.token != "" {
+		req.Header.Set("Authorization", "Bearer "+i.token)
+	}
+
+	var out ipinfoInfo
+	if err := do(req, &out, nil); err != nil {
+		return nil, err
+	}
+	if out.Bogon {
+		return nil, ErrPrivateIP
+	}
+	return out.toInfo(), nil
+}
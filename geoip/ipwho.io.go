@@ -95,17 +95,32 @@ type ipwhoConnection struct {
 
 func (i *ipwhoInfo) toInfo() *Info {
 	return &Info{
-		IP:         i.IP,
-		Country:    i.Country,
-		Region:     i.Region,
-		RegionCode: i.RegionCode,
-		City:       i.City,
-		CityCode:   i.Postal, // Use postal code as city code
-		ISP:        i.Connection.ISP,
-		Address:    i.Country + " " + i.Region + " " + i.City + " " + i.Connection.Org,
+		IP:          i.IP,
+		Country:     i.Country,
+		CountryCode: i.CountryCode,
+		Region:      i.Region,
+		RegionCode:  i.RegionCode,
+		City:        i.City,
+		CityCode:    i.Postal, // Use postal code as city code
+		ISP:         i.Connection.ISP,
+		Address:     joinNonEmpty(" ", i.Country, i.Region, i.City, i.Connection.Org),
+		Latitude:    i.Latitude,
+		Longitude:   i.Longitude,
+		Postal:      i.Postal,
+		ASN:         i.Connection.ASN,
+		Org:         i.Connection.Org,
 	}
 }
 
+func init() {
+	Register(Provider{
+		Name:        "ipwho",
+		Description: "ipwho.is",
+		Languages:   []Language{English},
+		New:         func(Credentials) (IPer, error) { return NewIPwho(), nil },
+	})
+}
+
 // IPwho 实现IPer接口的结构体
 type IPwho struct{}
 
@@ -0,0 +1,230 @@
+package geoip
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// subdivisionsCSV ISO 3166-2 一级行政区，code,name，由 iso-codes 整理
+//
+//go:embed data/subdivisions.csv
+var subdivisionsCSV string
+
+// Country ISO 3166-1 国家代码
+type Country struct {
+	Alpha2  string // 二位字母代码，如 CN
+	Alpha3  string // 三位字母代码，如 CHN
+	Numeric string // 三位数字代码，如 156
+}
+
+// Name 返回国家在指定语言下的名称
+func (c Country) Name(lang Language) string {
+	return CountryName(c.Alpha2, lang)
+}
+
+// LookupCountry 根据二位/三位/数字代码或任一内置语言的名称、别名查找国家
+// 例如 "CN"、"chn"、"156"、"China"、"中国"、"People's Republic of China" 均返回中国
+func LookupCountry(s string) (Country, bool) {
+	c := names().lookupCountry(s)
+	if c == nil {
+		return Country{}, false
+	}
+	return Country{Alpha2: c.alpha2, Alpha3: c.alpha3, Numeric: c.numeric}, true
+}
+
+// NormalizeCountry 返回国家的 ISO 3166-1 二位代码，无法识别时返回空字符串
+func NormalizeCountry(s string) string {
+	c, _ := LookupCountry(s)
+	return c.Alpha2
+}
+
+func (t *nameTable) lookupCountry(s string) *countryEntry {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if isCountryCode(s) {
+		code := strings.ToUpper(s)
+		if len(code) == 2 {
+			if c := t.countries[code]; c != nil {
+				return c
+			}
+		} else if c := t.countryByCode[strings.Repeat("0", 3-len(code))+code]; c != nil {
+			return c
+		}
+	}
+	return t.countryByAnyName(s)
+}
+
+// isCountryCode 是否为二位/三位字母代码或 1-3 位数字代码的形式
+func isCountryCode(s string) bool {
+	if len(s) < 1 || len(s) > 3 {
+		return false
+	}
+	digits, letters := 0, 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			letters++
+		}
+	}
+	return digits == len(s) || (letters == len(s) && len(s) >= 2)
+}
+
+// countryCode 港澳台在国际服务商中均作为独立的国家/地区返回
+func (p *provinceEntry) countryCode() string {
+	switch p.iso {
+	case "HK", "MO", "TW":
+		return p.iso
+	}
+	return "CN"
+}
+
+// subdivisionTable ISO 3166-2 一级行政区，可以通过 LoadSubdivisions 追加
+type subdivisionTable struct {
+	mu     sync.RWMutex
+	byCode map[string]string // CN-HB -> 名称
+	byName map[string]string // CN|hubei sheng -> CN-HB
+}
+
+var subdivisions = sync.OnceValue(func() *subdivisionTable {
+	t := subdivisionTable{
+		byCode: make(map[string]string),
+		byName: make(map[string]string),
+	}
+	for _, row := range readEmbeddedCSV(subdivisionsCSV)[1:] {
+		t.add(row[0], row[1:]...)
+	}
+	return &t
+})
+
+func (t *subdivisionTable) add(code string, names ...string) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	country, _, _ := strings.Cut(code, "-")
+	if _, ok := t.byCode[code]; !ok && len(names) > 0 {
+		t.byCode[code] = names[0]
+	}
+	for _, n := range names {
+		if key := normalizeName(n); key != "" {
+			t.byName[country+"|"+key] = code
+		}
+	}
+}
+
+// LoadSubdivisions 追加 ISO 3166-2 行政区及其别名
+// 每行为 code,name[,alias...]，例如 DE-BY,Bayern,Bavaria，首行不能是表头
+func LoadSubdivisions(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return err
+	}
+	t := subdivisions()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, row := range rows {
+		if len(row) < 2 || !strings.Contains(row[0], "-") {
+			return fmt.Errorf("subdivisions line %d: expected code,name", i+1)
+		}
+		t.add(row[0], row[1:]...)
+	}
+	return nil
+}
+
+// LookupSubdivision 根据国家与省/州的名称或代码返回 ISO 3166-2 代码
+// 例如 ("CN", "湖北省")、("China", "420000")、("US", "CA")、("US", "California") 分别返回 CN-HB、CN-HB、US-CA、US-CA
+func LookupSubdivision(country, region string) (string, bool) {
+	t := names()
+	c := t.lookupCountry(country)
+	if c == nil {
+		return "", false
+	}
+	return t.lookupSubdivision(c, region)
+}
+
+func (t *nameTable) lookupSubdivision(c *countryEntry, region string) (string, bool) {
+	region = strings.TrimSpace(region)
+	if region == "" {
+		return "", false
+	}
+	if c.alpha2 == "CN" {
+		// 行政区划代码，如 420000
+		if len(region) == 6 && region[2:] == "0000" {
+			if p := t.provinceByAdcode[region[:2]]; p != nil {
+				return "CN-" + p.iso, true
+			}
+		}
+		if p := t.province(region); p != nil {
+			return "CN-" + p.iso, true
+		}
+	}
+
+	s := subdivisions()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	code := strings.ToUpper(region)
+	if !strings.HasPrefix(code, c.alpha2+"-") {
+		code = c.alpha2 + "-" + code
+	}
+	if _, ok := s.byCode[code]; ok {
+		return code, true
+	}
+	code, ok := s.byName[c.alpha2+"|"+normalizeName(region)]
+	return code, ok
+}
+
+// SubdivisionName 返回 ISO 3166-2 代码对应的名称
+func SubdivisionName(code string) (string, bool) {
+	s := subdivisions()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	name, ok := s.byCode[strings.ToUpper(code)]
+	return name, ok
+}
+
+// Normalize 将国家统一为 ISO 3166-1 代码，并补全省/州的 ISO 3166-2 代码，无法识别国家时返回 false
+// Country 为空或为代码时填写 lang 语言的国家名称，lang 不在内置表中时使用英文
+// 只返回中国省份(如 pconline)的结果会补全国家
+// 已有的 Region/RegionCode 保持不变
+func (i *Info) Normalize(lang Language) bool {
+	t := names()
+	c := t.lookupCountry(i.CountryCode)
+	countryIsCode := false
+	if c == nil {
+		c = t.lookupCountry(i.Country)
+		countryIsCode = c != nil && isCountryCode(strings.TrimSpace(i.Country))
+	}
+	if c == nil && i.Country == "" && i.CountryCode == "" {
+		if p := t.province(i.Region); p != nil {
+			c = t.countries[p.countryCode()]
+		}
+	}
+	if c == nil {
+		return false
+	}
+
+	i.CountryCode, i.CountryAlpha3, i.CountryNumeric = c.alpha2, c.alpha3, c.numeric
+	if i.Country == "" || countryIsCode {
+		i.Country = c.name(lang)
+		if i.Country == "" {
+			i.Country = c.name(English)
+		}
+	}
+	if i.SubdivisionCode == "" {
+		code, ok := t.lookupSubdivision(c, i.RegionCode)
+		if !ok {
+			code, ok = t.lookupSubdivision(c, i.Region)
+		}
+		if ok {
+			i.SubdivisionCode = code
+		}
+	}
+	return true
+}
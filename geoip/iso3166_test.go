@@ -0,0 +1,125 @@
+package geoip
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLookupCountry(t *testing.T) {
+	for _, s := range []string{"CN", "chn", "156", "China", "中国", " china ", "People's Republic of China", "Chine"} {
+		c, ok := LookupCountry(s)
+		if !ok || c != (Country{Alpha2: "CN", Alpha3: "CHN", Numeric: "156"}) {
+			t.Fatalf("%q not match, got: %+v", s, c)
+		}
+	}
+	cases := map[string]string{
+		"USA":            "US",
+		"United Kingdom": "GB",
+		"UK":             "GB",
+		"Russia":         "RU",
+		"Turkey":         "TR",
+		"Curacao":        "CW",
+		"Hong Kong SAR":  "HK",
+		"8":              "AL",
+		"Atlantis":       "",
+	}
+	for in, want := range cases {
+		if got := NormalizeCountry(in); got != want {
+			t.Fatalf("%q not match, got: %s, want: %s", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	for in, want := range map[string]string{
+		" Curaçao ":     "curacao",
+		"Côte d'Ivoire": "cote d'ivoire",
+		"São Tomé":      "sao tome",
+		"Йемен":         "йемен",
+		"ガーナ":           "ガーナ",
+		"グアム":           "グアム",
+	} {
+		if got := normalizeName(in); got != want {
+			t.Fatalf("%q not match, got: %q", in, got)
+		}
+	}
+	for in, want := range map[string]string{"Йемен": "YE", "Япония": "JP", "イエメン": "YE"} {
+		if got := NormalizeCountry(in); got != want {
+			t.Fatalf("%q not match, got: %s", in, got)
+		}
+	}
+}
+
+func TestLookupSubdivision(t *testing.T) {
+	cases := []struct{ country, region, want string }{
+		{"CN", "湖北省", "CN-HB"},
+		{"China", "420000", "CN-HB"},
+		{"中国", "Inner Mongolia", "CN-NM"},
+		{"US", "CA", "US-CA"},
+		{"United States", "California", "US-CA"},
+		{"JP", "13", "JP-13"},
+		{"DE", "Bayern", "DE-BY"},
+		{"FR", "Ile-de-France", "FR-IDF"},
+	}
+	for _, c := range cases {
+		if got, ok := LookupSubdivision(c.country, c.region); !ok || got != c.want {
+			t.Fatalf("%s %s not match, got: %s", c.country, c.region, got)
+		}
+	}
+	if err := LoadSubdivisions(strings.NewReader("DE-BY,Bayern,Bavaria\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := LookupSubdivision("DE", "Bavaria"); !ok || got != "DE-BY" {
+		t.Fatalf("alias not match, got: %s", got)
+	}
+	if name, ok := SubdivisionName("de-by"); !ok || name != "Bayern" {
+		t.Fatalf("name not match, got: %s", name)
+	}
+	if err := LoadSubdivisions(strings.NewReader("code,name\n")); err == nil {
+		t.Fatal("expected error for header line")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	// pconline 只返回省份
+	info := Info{Region: "湖北省", City: "荆门市", RegionCode: "420000"}
+	if !info.Normalize(Chinese) {
+		t.Fatal("expected normalized")
+	}
+	if info.Country != "中国" || info.CountryCode != "CN" || info.CountryAlpha3 != "CHN" || info.CountryNumeric != "156" ||
+		info.SubdivisionCode != "CN-HB" || info.RegionCode != "420000" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+
+	info = Info{Country: "US", Region: "California"}
+	info.Normalize(English)
+	if info.Country != "United States" || info.CountryCode != "US" || info.SubdivisionCode != "US-CA" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+
+	info = Info{Region: "香港"}
+	info.Normalize(Chinese)
+	if info.CountryCode != "HK" || info.Country != "香港" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+
+	info = Info{Country: "Atlantis"}
+	if info.Normalize(English) || info.CountryCode != "" {
+		t.Fatalf("unknown country should not be normalized, got: %+v", info)
+	}
+}
+
+func TestEngineNormalize(t *testing.T) {
+	db, err := LoadCSV(strings.NewReader("183.95.0.0,183.95.255.255,,湖北省,荆门市,联通\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := New(Chinese, WithHandlers(db)).Lookup(context.Background(), "183.95.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Country != "中国" || info.CountryCode != "CN" || info.SubdivisionCode != "CN-HB" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+}
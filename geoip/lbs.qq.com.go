@@ -0,0 +1,165 @@
+package geoip
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tencentInfo 包含腾讯位置服务 IP 定位 API 返回的完整结构体
+//
+//	{
+//	    "status": 0,
+//	    "message": "Success",
+//	    "request_id": "8e4b2b1b4a6a4d2a9c0b6e1f2d3c4b5a",
+//	    "result": {
+//	        "ip": "183.95.255.255",
+//	        "location": {
+//	            "lat": 31.03546,
+//	            "lng": 112.19945
+//	        },
+//	        "ad_info": {
+//	            "nation": "中国",
+//	            "province": "湖北省",
+//	            "city": "荆门市",
+//	            "district": "东宝区",
+//	            "adcode": 420802,
+//	            "nation_code": 156
+//	        }
+//	    }
+//	}
+type tencentInfo struct {
+	Status    int           `json:"status"`     // 状态码 0:成功
+	Message   string        `json:"message"`    // 状态说明
+	RequestID string        `json:"request_id"` // 请求 ID
+	Result    tencentResult `json:"result"`     // 定位结果
+}
+
+// tencentResult 定位结果
+type tencentResult struct {
+	IP       string          `json:"ip"`       // IP 地址
+	Location tencentLocation `json:"location"` // 定位坐标，国测局坐标
+	AdInfo   tencentAdInfo   `json:"ad_info"`  // 行政区划信息
+}
+
+// tencentLocation 坐标
+type tencentLocation struct {
+	Lat float64 `json:"lat"` // 纬度
+	Lng float64 `json:"lng"` // 经度
+}
+
+// tencentAdInfo 行政区划信息
+type tencentAdInfo struct {
+	Nation     string `json:"nation"`      // 国家
+	Province   string `json:"province"`    // 省份
+	City       string `json:"city"`        // 城市
+	District   string `json:"district"`    // 区县
+	Adcode     int    `json:"adcode"`      // 行政区划代码，国外为 -1
+	NationCode int    `json:"nation_code"` // 国家代码 ISO 3166-1 数字代码
+}
+
+func (t *tencentInfo) toInfo() *Info {
+	r := t.Result
+	info := Info{
+		IP:      r.IP,
+		Country: r.AdInfo.Nation,
+		Region:  r.AdInfo.Province,
+		City:    r.AdInfo.City,
+		Address: r.AdInfo.Province + r.AdInfo.City + r.AdInfo.District,
+	}
+	if r.AdInfo.Adcode > 0 {
+		info.RegionCode, info.CityCode = splitAdcode(strconv.Itoa(r.AdInfo.Adcode))
+	}
+	info.Latitude, info.Longitude = gcj02ToWGS84(r.Location.Lat, r.Location.Lng)
+	return &info
+}
+
+func (t *tencentInfo) toError() error {
+	var err error
+	switch t.Status {
+	case 120, 121:
+		err = ErrQuotaExceeded
+	case 110, 111, 112, 113, 190, 199:
+		err = ErrUnauthorized
+	case 375:
+		err = ErrPrivateIP
+	case 382:
+		err = ErrNotFound
+	default:
+		return fmt.Errorf("腾讯位置服务API错误: %s (状态码: %d)", t.Message, t.Status)
+	}
+	return fmt.Errorf("腾讯位置服务API错误: %s (状态码: %d): %w", t.Message, t.Status, err)
+}
+
+func init() {
+	Register(Provider{
+		Name:        "tencent",
+		Description: "腾讯位置服务 IP 定位，需要 key，开启签名校验时需要 sk",
+		Languages:   []Language{Chinese},
+		Credentials: []Credential{{Name: "key"}, {Name: "sk", Optional: true}},
+		New:         func(c Credentials) (IPer, error) { return NewTencent(c["key"], c["sk"]), nil },
+	})
+}
+
+// Tencent 实现腾讯位置服务 IP 定位 API
+type Tencent struct {
+	key  string
+	sk   string
+	link string
+}
+
+// NewTencent 创建 Tencent 实例，sk 为空时不计算签名
+func NewTencent(key, sk string) IPer {
+	return &Tencent{
+		key:  key,
+		sk:   sk,
+		link: "https://apis.map.qq.com",
+	}
+}
+
+// Lookup 获取IP地理位置信息
+func (t *Tencent) Lookup(ctx context.Context, ip string) (*Info, error) {
+	const path = "/ws/location/v1/ip"
+	params := map[string]string{"ip": ip, "key": t.key}
+	query := url.Values{"ip": {ip}, "key": {t.key}}
+	if t.sk != "" {
+		query.Set("sig", tencentSig(path, params, t.sk))
+	}
+
+	var out tencentInfo
+	err := request(ctx, t.link+path+"?"+query.Encode(), &out, nil)
+	if err != nil {
+		return nil, err
+	}
+	if out.Status != 0 {
+		return nil, out.toError()
+	}
+	return out.toInfo(), nil
+}
+
+// tencentSig 计算腾讯位置服务签名
+// sig = md5(path + "?" + 按参数名升序拼接的未编码参数 + sk)
+func tencentSig(path string, params map[string]string, sk string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	b.WriteString(path + "?")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(k + "=" + params[k])
+	}
+	b.WriteString(sk)
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
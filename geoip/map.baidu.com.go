@@ -0,0 +1,156 @@
+package geoip
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// baiduInfo 包含百度地图普通 IP 定位 API 返回的完整结构体
+// address 格式为 "国家|省|市|区|运营商|..."
+//
+//	{
+//	    "address": "CN|湖北|荆门|None|UNICOM|0|0",
+//	    "content": {
+//	        "address": "湖北省荆门市",
+//	        "address_detail": {
+//	            "adcode": "420800",
+//	            "city": "荆门市",
+//	            "city_code": 217,
+//	            "district": "",
+//	            "province": "湖北省",
+//	            "street": "",
+//	            "street_number": ""
+//	        },
+//	        "point": {
+//	            "x": "112.19945",
+//	            "y": "31.03546"
+//	        }
+//	    },
+//	    "status": 0
+//	}
+type baiduInfo struct {
+	Address string       `json:"address"` // 简要地址信息
+	Content baiduContent `json:"content"` // 结构信息
+	Status  int          `json:"status"`  // 状态码 0:成功
+	Message string       `json:"message"` // 失败原因
+}
+
+// baiduContent 结构信息
+type baiduContent struct {
+	Address       string             `json:"address"`        // 简要地址
+	AddressDetail baiduAddressDetail `json:"address_detail"` // 结构化地址
+	Point         baiduPoint         `json:"point"`          // 城市中心点坐标
+}
+
+// baiduAddressDetail 结构化地址
+type baiduAddressDetail struct {
+	Adcode       string `json:"adcode"`        // 行政区划代码
+	City         string `json:"city"`          // 城市
+	CityCode     int    `json:"city_code"`     // 百度城市代码
+	District     string `json:"district"`      // 区县
+	Province     string `json:"province"`      // 省份
+	Street       string `json:"street"`        // 街道
+	StreetNumber string `json:"street_number"` // 门牌号
+}
+
+// baiduPoint 坐标，coor=gcj02 时为国测局坐标
+type baiduPoint struct {
+	X string `json:"x"` // 经度
+	Y string `json:"y"` // 纬度
+}
+
+func (b *baiduInfo) toInfo(ip string) *Info {
+	d := b.Content.AddressDetail
+	info := Info{
+		IP:      ip,
+		Country: "中国",
+		Region:  d.Province,
+		City:    d.City,
+		Address: d.Province + d.City,
+	}
+	info.RegionCode, info.CityCode = splitAdcode(d.Adcode)
+	if parts := strings.Split(b.Address, "|"); len(parts) > 4 && parts[4] != "None" {
+		info.ISP = parts[4]
+		info.Address += " " + parts[4]
+	}
+	lng, err1 := strconv.ParseFloat(b.Content.Point.X, 64)
+	lat, err2 := strconv.ParseFloat(b.Content.Point.Y, 64)
+	if err1 == nil && err2 == nil {
+		info.Latitude, info.Longitude = gcj02ToWGS84(lat, lng)
+	}
+	return &info
+}
+
+func (b *baiduInfo) toError() error {
+	var err error
+	switch {
+	case b.Status == 1, b.Status == 2:
+		// 国外 IP 或无法定位的 IP
+		err = ErrNotFound
+	case b.Status == 302 || b.Status == 401 || b.Status == 402:
+		err = ErrQuotaExceeded
+	case b.Status == 101 || b.Status == 102, b.Status >= 200 && b.Status < 300:
+		// 101/102 为 AK 或 MCODE 参数缺失，2xx 为 AK 无效或无权限
+		err = ErrUnauthorized
+	default:
+		return fmt.Errorf("百度地图API错误: %s (状态码: %d)", b.Message, b.Status)
+	}
+	return fmt.Errorf("百度地图API错误: %s (状态码: %d): %w", b.Message, b.Status, err)
+}
+
+func init() {
+	Register(Provider{
+		Name:        "baidu",
+		Description: "百度地图普通 IP 定位，需要 ak，开启 SN 校验时需要 sk",
+		Languages:   []Language{Chinese},
+		Credentials: []Credential{{Name: "ak"}, {Name: "sk", Optional: true}},
+		New:         func(c Credentials) (IPer, error) { return NewBaidu(c["ak"], c["sk"]), nil },
+	})
+}
+
+// Baidu 实现百度地图普通 IP 定位 API
+type Baidu struct {
+	ak   string
+	sk   string
+	link string
+}
+
+// NewBaidu 创建 Baidu 实例，sk 为空时不计算 SN 签名
+func NewBaidu(ak, sk string) IPer {
+	return &Baidu{
+		ak:   ak,
+		sk:   sk,
+		link: "https://api.map.baidu.com",
+	}
+}
+
+// Lookup 获取IP地理位置信息
+func (b *Baidu) Lookup(ctx context.Context, ip string) (*Info, error) {
+	const path = "/location/ip"
+	query := url.Values{"ip": {ip}, "ak": {b.ak}, "coor": {"gcj02"}}.Encode()
+	if b.sk != "" {
+		query += "&sn=" + baiduSN(path, query, b.sk)
+	}
+
+	var out baiduInfo
+	err := request(ctx, b.link+path+"?"+query, &out, nil)
+	if err != nil {
+		return nil, err
+	}
+	if out.Status != 0 {
+		return nil, out.toError()
+	}
+	return out.toInfo(ip), nil
+}
+
+// baiduSN 计算百度地图 SN 签名
+// sn = md5(urlencode(path + "?" + query + sk))，query 需与实际请求参数顺序一致
+func baiduSN(path, query, sk string) string {
+	sum := md5.Sum([]byte(url.QueryEscape(path + "?" + query + sk)))
+	return hex.EncodeToString(sum[:])
+}
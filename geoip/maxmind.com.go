@@ -0,0 +1,226 @@
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// maxmindInfo contains the IP geolocation information struct returned by MaxMind GeoIP2 Precision web services
+// Names are keyed by locale code (en, de, es, fr, ja, pt-BR, ru, zh-CN)
+//
+//	{
+//	    "city": {"geoname_id": 5375480, "names": {"en": "Mountain View", "zh-CN": "芒廷维尤"}},
+//	    "continent": {"code": "NA", "geoname_id": 6255149, "names": {"en": "North America"}},
+//	    "country": {"geoname_id": 6252001, "iso_code": "US", "names": {"en": "United States", "zh-CN": "美国"}},
+//	    "location": {"accuracy_radius": 1000, "latitude": 37.751, "longitude": -97.822, "time_zone": "America/Chicago"},
+//	    "postal": {"code": "94043"},
+//	    "subdivisions": [{"geoname_id": 5332921, "iso_code": "CA", "names": {"en": "California"}}],
+//	    "traits": {
+//	        "autonomous_system_number": 15169,
+//	        "autonomous_system_organization": "GOOGLE",
+//	        "isp": "Google",
+//	        "organization": "Google",
+//	        "ip_address": "8.8.8.8",
+//	        "network": "8.8.8.0/24"
+//	    },
+//	    "maxmind": {"queries_remaining": 54321}
+//	}
+type maxmindInfo struct {
+	City         maxmindRecord   `json:"city"`         // City
+	Continent    maxmindRecord   `json:"continent"`    // Continent
+	Country      maxmindRecord   `json:"country"`      // Country
+	Location     maxmindLocation `json:"location"`     // Location
+	Postal       maxmindRecord   `json:"postal"`       // Postal code
+	Subdivisions []maxmindRecord `json:"subdivisions"` // Subdivisions, from largest to smallest
+	Traits       maxmindTraits   `json:"traits"`       // Network traits
+}
+
+// maxmindRecord named record struct
+type maxmindRecord struct {
+	Code      string            `json:"code"`       // Continent or postal code
+	GeonameID int               `json:"geoname_id"` // GeoNames id
+	ISOCode   string            `json:"iso_code"`   // ISO 3166 code
+	Names     map[string]string `json:"names"`      // Localized names
+}
+
+// maxmindLocation location struct
+type maxmindLocation struct {
+	AccuracyRadius int     `json:"accuracy_radius"` // Accuracy radius in kilometers
+	Latitude       float64 `json:"latitude"`        // Latitude
+	Longitude      float64 `json:"longitude"`       // Longitude
+	TimeZone       string  `json:"time_zone"`       // IANA time zone
+}
+
+// maxmindTraits network traits struct
+type maxmindTraits struct {
+	ASN                int    `json:"autonomous_system_number"`       // ASN number
+	ASNOrganization    string `json:"autonomous_system_organization"` // ASN organization
+	ISP                string `json:"isp"`                            // Internet Service Provider
+	Organization       string `json:"organization"`                   // Organization
+	Domain             string `json:"domain"`                         // Domain
+	IPAddress          string `json:"ip_address"`                     // Queried IP address
+	Network            string `json:"network"`                        // Network CIDR
+	UserType           string `json:"user_type"`                      // business, cafe, hosting, residential ...
+	IsAnonymous        bool   `json:"is_anonymous"`                   // Anonymous network
+	IsAnonymousVPN     bool   `json:"is_anonymous_vpn"`               // VPN
+	IsHostingProvider  bool   `json:"is_hosting_provider"`            // Hosting provider
+	IsPublicProxy      bool   `json:"is_public_proxy"`                // Public proxy
+	IsResidentialProxy bool   `json:"is_residential_proxy"`           // Residential proxy
+	IsTorExitNode      bool   `json:"is_tor_exit_node"`               // Tor exit node
+	IsAnycast          bool   `json:"is_anycast"`                     // Anycast network
+	ConnectionType     string `json:"connection_type"`                // Connection type
+	MobileCountryCode  string `json:"mobile_country_code"`            // MCC
+	MobileNetworkCode  string `json:"mobile_network_code"`            // MNC
+	IsLegitimateProxy  bool   `json:"is_legitimate_proxy"`            // Corporate proxy
+}
+
+// maxmindError error response struct
+//
+//	{"code": "IP_ADDRESS_RESERVED", "error": "The value 10.0.0.1 belongs to a reserved or private range."}
+type maxmindError struct {
+	Code  string `json:"code"`  // Error code
+	Error string `json:"error"` // Error message
+}
+
+func (m *maxmindInfo) toInfo() *Info {
+	const lang = "en"
+	info := Info{
+		IP:             m.Traits.IPAddress,
+		Country:        m.Country.Names[lang],
+		CountryCode:    m.Country.ISOCode,
+		City:           m.City.Names[lang],
+		ISP:            m.Traits.ISP,
+		Latitude:       m.Location.Latitude,
+		Longitude:      m.Location.Longitude,
+		AccuracyRadius: m.Location.AccuracyRadius,
+		Postal:         m.Postal.Code,
+		Timezone:       m.Location.TimeZone,
+		ASN:            m.Traits.ASN,
+		Org:            m.Traits.ASNOrganization,
+		Prefix:         m.Traits.Network,
+	}
+	if len(m.Subdivisions) > 0 {
+		info.Region = m.Subdivisions[0].Names[lang]
+		info.RegionCode = m.Subdivisions[0].ISOCode
+	}
+	if info.ISP == "" {
+		info.ISP = m.Traits.ASNOrganization
+	}
+	if m.Traits.Organization != "" {
+		info.Org = m.Traits.Organization
+	}
+	t := m.Traits
+	if t.IsAnonymous || t.IsAnonymousVPN || t.IsHostingProvider || t.IsPublicProxy || t.IsResidentialProxy || t.IsTorExitNode {
+		info.Privacy = &Privacy{
+			VPN:     t.IsAnonymousVPN,
+			Proxy:   t.IsPublicProxy || t.IsResidentialProxy,
+			Tor:     t.IsTorExitNode,
+			Hosting: t.IsHostingProvider,
+		}
+	}
+	info.Address = joinNonEmpty(" ", info.Country, info.Region, info.City, info.Org)
+	info.Localized = m.localized()
+	return &info
+}
+
+// localized collects names of every locale returned
+func (m *maxmindInfo) localized() map[Language]Names {
+	out := make(map[Language]Names)
+	set := func(names map[string]string, field func(*Names) *string) {
+		for lang, name := range names {
+			n := out[Language(lang)]
+			*field(&n) = name
+			out[Language(lang)] = n
+		}
+	}
+	set(m.Country.Names, func(n *Names) *string { return &n.Country })
+	if len(m.Subdivisions) > 0 {
+		set(m.Subdivisions[0].Names, func(n *Names) *string { return &n.Region })
+	}
+	set(m.City.Names, func(n *Names) *string { return &n.City })
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// toError 按错误码归类，无法识别的错误码保留状态码的归类
+func (e *maxmindError) toError(se *StatusError) error {
+	var err error
+	switch e.Code {
+	case "IP_ADDRESS_RESERVED":
+		err = ErrPrivateIP
+	case "IP_ADDRESS_NOT_FOUND":
+		err = ErrNotFound
+	case "INSUFFICIENT_FUNDS", "OUT_OF_QUERIES":
+		err = ErrQuotaExceeded
+	case "AUTHORIZATION_INVALID", "ACCOUNT_ID_REQUIRED", "ACCOUNT_ID_UNKNOWN", "LICENSE_KEY_REQUIRED", "PERMISSION_REQUIRED":
+		err = ErrUnauthorized
+	default:
+		return fmt.Errorf("maxmind error %s: %s: %w", e.Code, e.Error, se)
+	}
+	return fmt.Errorf("maxmind error %s: %s: %w", e.Code, e.Error, err)
+}
+
+// MaxMind web service editions
+const (
+	MaxMindCountry  = "country"
+	MaxMindCity     = "city"
+	MaxMindInsights = "insights"
+)
+
+func init() {
+	Register(Provider{
+		Name:        "maxmind",
+		Description: "MaxMind GeoIP2 Precision web services, requires account_id and license_key",
+		Languages:   []Language{English},
+		Credentials: []Credential{{Name: "account_id"}, {Name: "license_key"}, {Name: "edition", Optional: true}},
+		New: func(c Credentials) (IPer, error) {
+			return NewMaxMind(c["account_id"], c["license_key"], c["edition"]), nil
+		},
+	})
+}
+
+// MaxMind implements IPer interface for MaxMind GeoIP2 Precision web services
+type MaxMind struct {
+	accountID  string
+	licenseKey string
+	link       string
+}
+
+// NewMaxMind creates MaxMind instance
+// edition is one of MaxMindCountry, MaxMindCity, MaxMindInsights, defaults to MaxMindCity
+func NewMaxMind(accountID, licenseKey, edition string) IPer {
+	if edition == "" {
+		edition = MaxMindCity
+	}
+	return &MaxMind{
+		accountID:  accountID,
+		licenseKey: licenseKey,
+		link:       "https://geoip.maxmind.com/geoip/v2.1/" + edition + "/",
+	}
+}
+
+// Lookup retrieves IP geolocation information
+func (m *MaxMind) Lookup(ctx context.Context, ip string) (*Info, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.link+ip, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(m.accountID, m.licenseKey)
+	req.Header.Set("Accept", "application/json")
+
+	var out maxmindInfo
+	if err := do(req, &out, nil); err != nil {
+		var se *StatusError
+		var me maxmindError
+		if errors.As(err, &se) && json.Unmarshal(se.Body, &me) == nil && me.Code != "" {
+			return nil, me.toError(se)
+		}
+		return nil, err
+	}
+	return out.toInfo(), nil
+}
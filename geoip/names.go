@@ -0,0 +1,313 @@
+package geoip
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"maps"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// countriesCSV ISO 3166-1 国家表，代码、别名(| 分隔)及各语言名称，由 iso-codes 整理
+//
+//go:embed data/countries.csv
+var countriesCSV string
+
+// cnProvincesCSV 中国省级行政区名称表
+//
+//go:embed data/cn_provinces.csv
+var cnProvincesCSV string
+
+// Names 某一语言下的地名
+type Names struct {
+	Country string
+	Region  string
+	City    string
+}
+
+// countryEntry 国家表中的一项
+type countryEntry struct {
+	alpha2  string
+	alpha3  string
+	numeric string
+	names   map[Language]string
+}
+
+// name 返回指定语言的名称，没有完全匹配时按主语言匹配，例如 zh 匹配 zh-CN
+func (c *countryEntry) name(lang Language) string {
+	if n, ok := c.names[lang]; ok {
+		return n
+	}
+	base := baseLanguage(lang)
+	for l, n := range c.names {
+		if baseLanguage(l) == base {
+			return n
+		}
+	}
+	return ""
+}
+
+// provinceEntry 省级行政区名称表中的一项
+type provinceEntry struct {
+	adcode string // 行政区划代码，如 420000
+	iso    string // ISO 3166-2 代码后缀，如 HB
+	zh     string // 全称，如 湖北省
+	short  string // 简称，如 湖北
+	en     string // 英文，如 Hubei
+}
+
+// name 中文返回全称，其它语言返回拼音
+func (p *provinceEntry) name(lang Language) string {
+	if baseLanguage(lang) == "zh" {
+		return p.zh
+	}
+	return p.en
+}
+
+type nameTable struct {
+	countries        map[string]*countryEntry // alpha2
+	countryByCode    map[string]*countryEntry // alpha3 及 numeric
+	countryByName    map[string]*countryEntry // 各语言名称及别名，见 normalizeName
+	provinceByName   map[string]*provinceEntry
+	provinceByAdcode map[string]*provinceEntry // 省级代码前两位
+}
+
+// names 延迟解析内嵌的名称表
+var names = sync.OnceValue(func() *nameTable {
+	t := nameTable{
+		countries:        make(map[string]*countryEntry),
+		countryByCode:    make(map[string]*countryEntry),
+		countryByName:    make(map[string]*countryEntry),
+		provinceByName:   make(map[string]*provinceEntry),
+		provinceByAdcode: make(map[string]*provinceEntry),
+	}
+
+	rows := readEmbeddedCSV(countriesCSV)
+	header := rows[0]
+	for _, row := range rows[1:] {
+		c := countryEntry{names: make(map[Language]string, len(header))}
+		var aliases []string
+		for i := 0; i < len(header) && i < len(row); i++ {
+			switch header[i] {
+			case "alpha2":
+				c.alpha2 = row[i]
+			case "alpha3":
+				c.alpha3 = row[i]
+			case "numeric":
+				c.numeric = row[i]
+			case "aliases":
+				aliases = strings.Split(row[i], "|")
+			default:
+				c.names[Language(header[i])] = row[i]
+				aliases = append(aliases, row[i])
+			}
+		}
+		for _, n := range aliases {
+			if key := normalizeName(n); key != "" && t.countryByName[key] == nil {
+				t.countryByName[key] = &c
+			}
+		}
+		t.countries[c.alpha2] = &c
+		t.countryByCode[c.alpha3] = &c
+		t.countryByCode[c.numeric] = &c
+	}
+
+	for _, row := range readEmbeddedCSV(cnProvincesCSV)[1:] {
+		p := provinceEntry{adcode: row[0], iso: row[1], zh: row[2], short: row[3], en: row[4]}
+		for _, n := range []string{p.zh, p.short, p.en} {
+			t.provinceByName[normalizeName(n)] = &p
+		}
+		t.provinceByAdcode[p.adcode[:2]] = &p
+	}
+	for alias, en := range provinceAliases {
+		t.provinceByName[alias] = t.provinceByName[normalizeName(en)]
+	}
+	return &t
+})
+
+// provinceAliases 常见的省级行政区别名
+var provinceAliases = map[string]string{
+	"nei mongol": "Inner Mongolia",
+	"xizang":     "Tibet",
+	"macao":      "Macau",
+	"hongkong":   "Hong Kong",
+	"shensi":     "Shaanxi",
+}
+
+// provinceSuffixesEn 英文省级行政区名称常见的后缀
+var provinceSuffixesEn = []string{
+	" uyghur autonomous region", " zhuang autonomous region", " hui autonomous region",
+	" autonomous region", " special administrative region", " municipality", " province", " sheng", " shi",
+}
+
+func (t *nameTable) country(code string) *countryEntry {
+	return t.countries[strings.ToUpper(code)]
+}
+
+func (t *nameTable) countryByAnyName(name string) *countryEntry {
+	return t.countryByName[normalizeName(name)]
+}
+
+func (t *nameTable) province(name string) *provinceEntry {
+	key := normalizeName(name)
+	if p, ok := t.provinceByName[key]; ok {
+		return p
+	}
+	for _, suffix := range provinceSuffixesEn {
+		if s, ok := strings.CutSuffix(key, suffix); ok {
+			return t.provinceByName[s]
+		}
+	}
+	return nil
+}
+
+// CountryName 返回 ISO 3166-1 二位国家代码在指定语言下的名称，未知时返回空字符串
+func CountryName(code string, lang Language) string {
+	if c := names().country(code); c != nil {
+		return c.name(lang)
+	}
+	return ""
+}
+
+// TranslateCountry 将任一内置语言的国家名称翻译为指定语言，未知时返回空字符串
+func TranslateCountry(name string, lang Language) string {
+	if c := names().countryByAnyName(name); c != nil {
+		return c.name(lang)
+	}
+	return ""
+}
+
+// Localize 补全 Localized 中 langs 各语言的国家、中国省份及地级市名称，
+// 并按 langs 的顺序逐个字段回退，改写 Country/Region/City
+// Localized 为空时，先根据是否包含汉字将当前名称记为 Chinese 或 English
+func (i *Info) Localize(langs ...Language) {
+	if len(langs) == 0 {
+		return
+	}
+	t := names()
+	m := maps.Clone(i.Localized)
+	if len(m) == 0 {
+		m = make(map[Language]Names, len(langs)+1)
+		m[detectLanguage(i)] = Names{Country: i.Country, Region: i.Region, City: i.City}
+	}
+
+	c := t.country(i.CountryCode)
+	for _, n := range m {
+		if c != nil {
+			break
+		}
+		c = t.countryByAnyName(n.Country)
+	}
+	var region *provinceEntry
+	var city interface{ name(Language) string }
+	if c == nil || c.alpha2 == "CN" {
+		for _, n := range m {
+			if region == nil {
+				region = t.province(n.Region)
+			}
+		}
+		for _, n := range m {
+			if city != nil {
+				break
+			}
+			// 直辖市的城市名与省级名称相同
+			if p := t.province(n.City); p != nil && municipalities[p.adcode[:2]] {
+				city = p
+			} else if region != nil {
+				if d := divisions().city(region.adcode[:2], n.City); d != nil {
+					city = d
+				}
+			}
+		}
+	}
+
+	for _, lang := range langs {
+		n := m[lang]
+		if n.Country == "" && c != nil {
+			n.Country = c.name(lang)
+		}
+		if n.Region == "" && region != nil {
+			n.Region = region.name(lang)
+		}
+		if n.City == "" && city != nil {
+			n.City = city.name(lang)
+		}
+		if n != (Names{}) {
+			m[lang] = n
+		}
+	}
+	i.Localized = m
+
+	pick := func(current string, field func(Names) string) string {
+		for _, lang := range langs {
+			if v := field(m[lang]); v != "" {
+				return v
+			}
+		}
+		return current
+	}
+	i.Country = pick(i.Country, func(n Names) string { return n.Country })
+	i.Region = pick(i.Region, func(n Names) string { return n.Region })
+	i.City = pick(i.City, func(n Names) string { return n.City })
+}
+
+// detectLanguage 包含汉字时认为是 Chinese，否则为 English
+func detectLanguage(i *Info) Language {
+	for _, r := range i.Country + i.Region + i.City {
+		if unicode.Is(unicode.Han, r) {
+			return Chinese
+		}
+	}
+	return English
+}
+
+// baseLanguage 返回主语言，例如 zh-CN 返回 zh
+func baseLanguage(lang Language) string {
+	base, _, _ := strings.Cut(string(lang), "-")
+	return strings.ToLower(base)
+}
+
+// normalizeName 用于名称匹配的键：去除首尾空白、转小写并去掉拉丁字母的变音符号，如 Curaçao 与 curacao 相同
+func normalizeName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			return foldLatin(s)
+		}
+	}
+	return s
+}
+
+// foldLatin 只去掉拉丁字母上的组合符号，日文浊点、俄文 й 等其它文字的组合符号保持不变
+func foldLatin(s string) string {
+	d := norm.NFD.String(s)
+	var b strings.Builder
+	b.Grow(len(d))
+	latin := false
+	for _, r := range d {
+		if unicode.Is(unicode.Mn, r) {
+			if latin {
+				continue
+			}
+		} else {
+			latin = unicode.Is(unicode.Latin, r)
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// readEmbeddedCSV 解析内嵌的 CSV，数据有误时 panic
+func readEmbeddedCSV(data string) [][]string {
+	r := csv.NewReader(strings.NewReader(data))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		panic("geoip: invalid embedded csv: " + err.Error())
+	}
+	return rows
+}
@@ -0,0 +1,90 @@
+package geoip
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCountryName(t *testing.T) {
+	cases := []struct {
+		code string
+		lang Language
+		want string
+	}{
+		{"CN", Chinese, "中国"},
+		{"us", English, "United States"},
+		{"JP", Japanese, "日本"},
+		{"DE", German, "Deutschland"},
+		{"BR", PortugueseBR, "Brasil"},
+		{"BR", Language("pt"), "Brasil"},
+		{"XX", English, ""},
+	}
+	for _, c := range cases {
+		if got := CountryName(c.code, c.lang); got != c.want {
+			t.Fatalf("%s %s not match, got: %s", c.code, c.lang, got)
+		}
+	}
+	if got := TranslateCountry("美国", English); got != "United States" {
+		t.Fatalf("translate not match, got: %s", got)
+	}
+	if got := TranslateCountry("japan", Chinese); got != "日本" {
+		t.Fatalf("translate not match, got: %s", got)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	// 中文结果渲染为英文
+	info := Info{Country: "中国", Region: "湖北省", City: "荆门市"}
+	info.Localize(English, Chinese)
+	if info.Country != "China" || info.Region != "Hubei" || info.City != "Jingmen" {
+		t.Fatalf("english not match, got: %+v", info)
+	}
+	if n := info.Localized[Chinese]; n.Country != "中国" || n.Region != "湖北省" || n.City != "荆门市" {
+		t.Fatalf("chinese names not match, got: %+v", n)
+	}
+
+	// 英文结果渲染为中文，直辖市的城市同样翻译
+	info = Info{Country: "China", CountryCode: "CN", Region: "Beijing", City: "Beijing"}
+	info.Localize(Chinese)
+	if info.Country != "中国" || info.Region != "北京市" || info.City != "北京市" {
+		t.Fatalf("chinese not match, got: %+v", info)
+	}
+
+	// 已有的本地化名称优先，缺失的字段按顺序回退
+	info = Info{
+		Country: "United States", CountryCode: "US", City: "Mountain View",
+		Localized: map[Language]Names{
+			English:  {Country: "United States", City: "Mountain View"},
+			Japanese: {Country: "アメリカ"},
+		},
+	}
+	shared := info.Localized
+	info.Localize(Japanese, English)
+	if info.Country != "アメリカ" || info.City != "Mountain View" {
+		t.Fatalf("fallback not match, got: %+v", info)
+	}
+	if _, ok := shared[Chinese]; ok || len(shared) != 2 {
+		t.Fatalf("localize must not modify the original map, got: %+v", shared)
+	}
+}
+
+func TestEngineLanguages(t *testing.T) {
+	db, err := LoadCSV(strings.NewReader("183.95.0.0,183.95.255.255,中国,湖北省,荆门市,联通\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(Chinese, WithHandlers(db), WithLanguages(English))
+	info, err := e.Lookup(context.Background(), "183.95.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Country != "China" || info.Region != "Hubei" || info.Localized[English].Country != "China" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+
+	e = New(English, WithLanguages(Japanese, English))
+	if p, ok := e.handlers[0].(*IPapi); !ok || p.lang != Japanese {
+		t.Fatalf("ipapi language not match, got: %#v", e.handlers[0])
+	}
+}
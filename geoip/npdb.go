@@ -0,0 +1,260 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+)
+
+// npdb 紧凑的二进制 IP 段数据库，由 DBBuilder 生成，LoadDB/OpenDB 加载
+//
+//	魔数 "NPDB" + 版本(1 字节)
+//	字符串表 uvarint 数量，每项 uvarint 长度 + 字节
+//	信息表   uvarint 数量，每项 uvarint 字段数 + 字段
+//	IP 段表  uvarint 数量，每项 地址长度(4/16) + 起始 IP + 结束 IP + uvarint 信息序号
+//
+// 字段为 tag(1 字节) + 值，值的编码由 tag 范围决定，旧版本遇到未知 tag 可以跳过
+//
+//	0-31   uvarint 字符串表序号
+//	32-63  float64 小端序
+//	64-127 uvarint
+const (
+	npdbMagic   = "NPDB"
+	npdbVersion = 1
+
+	npdbTagFloat = 32
+	npdbTagNum   = 64
+)
+
+// npdbField Info 中写入数据库的字段
+type npdbField struct {
+	tag byte
+	str func(*Info) *string
+	f64 func(*Info) *float64
+	num func(*Info) *int
+}
+
+// npdbFields 已分配的 tag 不能修改，新增字段使用新的 tag
+var npdbFields = []npdbField{
+	{tag: 0, str: func(i *Info) *string { return &i.Country }},
+	{tag: 1, str: func(i *Info) *string { return &i.CountryCode }},
+	{tag: 2, str: func(i *Info) *string { return &i.Region }},
+	{tag: 3, str: func(i *Info) *string { return &i.RegionCode }},
+	{tag: 4, str: func(i *Info) *string { return &i.City }},
+	{tag: 5, str: func(i *Info) *string { return &i.CityCode }},
+	{tag: 6, str: func(i *Info) *string { return &i.ISP }},
+	{tag: 7, str: func(i *Info) *string { return &i.Address }},
+	{tag: 8, str: func(i *Info) *string { return &i.Postal }},
+	{tag: 9, str: func(i *Info) *string { return &i.Timezone }},
+	{tag: 10, str: func(i *Info) *string { return &i.Org }},
+	{tag: 11, str: func(i *Info) *string { return &i.CountryAlpha3 }},
+	{tag: 12, str: func(i *Info) *string { return &i.CountryNumeric }},
+	{tag: 13, str: func(i *Info) *string { return &i.SubdivisionCode }},
+	{tag: 14, str: func(i *Info) *string { return &i.Prefix }},
+	{tag: 15, str: func(i *Info) *string { return &i.Registry }},
+	{tag: npdbTagFloat, f64: func(i *Info) *float64 { return &i.Latitude }},
+	{tag: npdbTagFloat + 1, f64: func(i *Info) *float64 { return &i.Longitude }},
+	{tag: npdbTagNum, num: func(i *Info) *int { return &i.ASN }},
+	{tag: npdbTagNum + 1, num: func(i *Info) *int { return &i.AccuracyRadius }},
+}
+
+func npdbFieldByTag(tag byte) (npdbField, bool) {
+	for _, f := range npdbFields {
+		if f.tag == tag {
+			return f, true
+		}
+	}
+	return npdbField{}, false
+}
+
+// encodeInfo 编码 Info 的非零字段，intern 返回字符串在字符串表中的序号
+func encodeInfo(info *Info, intern func(string) uint64) []byte {
+	var n uint64
+	fields := make([]byte, 0, 32)
+	for _, f := range npdbFields {
+		switch {
+		case f.str != nil:
+			s := *f.str(info)
+			if s == "" {
+				continue
+			}
+			fields = binary.AppendUvarint(append(fields, f.tag), intern(s))
+		case f.f64 != nil:
+			v := *f.f64(info)
+			if v == 0 {
+				continue
+			}
+			fields = binary.LittleEndian.AppendUint64(append(fields, f.tag), math.Float64bits(v))
+		case f.num != nil:
+			v := *f.num(info)
+			if v == 0 {
+				continue
+			}
+			fields = binary.AppendUvarint(append(fields, f.tag), uint64(v))
+		}
+		n++
+	}
+	return append(binary.AppendUvarint(nil, n), fields...)
+}
+
+// npdbReader 顺序读取，出错后后续读取均返回零值，由调用方检查 err
+type npdbReader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *npdbReader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = fmt.Errorf("npdb: offset %d: %s", r.off, fmt.Sprintf(format, args...))
+	}
+}
+
+func (r *npdbReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.off:])
+	if n <= 0 {
+		r.fail("invalid uvarint")
+		return 0
+	}
+	r.off += n
+	return v
+}
+
+func (r *npdbReader) bytes(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(len(r.data)-r.off) {
+		r.fail("unexpected end of data")
+		return nil
+	}
+	b := r.data[r.off : r.off+int(n)]
+	r.off += int(n)
+	return b
+}
+
+// count 读取数量，每项至少占 min 字节，防止损坏的文件导致超大分配
+func (r *npdbReader) count(min int) int {
+	n := r.uvarint()
+	if n > uint64((len(r.data)-r.off)/min) {
+		r.fail("count %d exceeds data size", n)
+		return 0
+	}
+	return int(n)
+}
+
+func (r *npdbReader) info(strs []string) *Info {
+	var info Info
+	n := r.count(2)
+	for range n {
+		tag := r.bytes(1)
+		if r.err != nil {
+			return nil
+		}
+		f, known := npdbFieldByTag(tag[0])
+		switch {
+		case tag[0] < npdbTagFloat:
+			idx := r.uvarint()
+			if idx >= uint64(len(strs)) {
+				r.fail("string index %d out of range", idx)
+				return nil
+			}
+			if known {
+				*f.str(&info) = strs[idx]
+			}
+		case tag[0] < npdbTagNum:
+			b := r.bytes(8)
+			if known && b != nil {
+				*f.f64(&info) = math.Float64frombits(binary.LittleEndian.Uint64(b))
+			}
+		case tag[0] < 128:
+			v := r.uvarint()
+			if known {
+				*f.num(&info) = int(v)
+			}
+		default:
+			r.fail("invalid field tag %d", tag[0])
+			return nil
+		}
+	}
+	return &info
+}
+
+func (r *npdbReader) addr() netip.Addr {
+	size := r.bytes(1)
+	if r.err != nil {
+		return netip.Addr{}
+	}
+	b := r.bytes(uint64(size[0]))
+	if r.err != nil {
+		return netip.Addr{}
+	}
+	addr, ok := netip.AddrFromSlice(b)
+	if !ok {
+		r.fail("invalid address length %d", size[0])
+	}
+	return addr
+}
+
+// OpenDB 读取 DBBuilder 生成的数据库文件
+func OpenDB(path string) (*RangeDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDB(data)
+}
+
+// LoadDB 读取 DBBuilder 生成的数据库，数据全部加载到内存
+func LoadDB(r io.Reader) (*RangeDB, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseDB(data)
+}
+
+func parseDB(data []byte) (*RangeDB, error) {
+	if len(data) < len(npdbMagic)+1 || string(data[:len(npdbMagic)]) != npdbMagic {
+		return nil, errors.New("npdb: invalid magic")
+	}
+	if v := data[len(npdbMagic)]; v != npdbVersion {
+		return nil, fmt.Errorf("npdb: unsupported version %d", v)
+	}
+	r := npdbReader{data: data, off: len(npdbMagic) + 1}
+
+	strs := make([]string, r.count(1))
+	for i := range strs {
+		strs[i] = string(r.bytes(r.uvarint()))
+	}
+	infos := make([]*Info, r.count(1))
+	for i := range infos {
+		infos[i] = r.info(strs)
+	}
+	ranges := make([]ipRange, r.count(10))
+	for i := range ranges {
+		start, end := r.addr(), r.addr()
+		idx := r.uvarint()
+		if r.err == nil && idx >= uint64(len(infos)) {
+			r.fail("info index %d out of range", idx)
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		ranges[i] = ipRange{start: start, end: end, info: infos[idx], line: i + 1}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.off != len(data) {
+		return nil, errors.New("npdb: trailing data")
+	}
+	return newRangeDB(ranges)
+}
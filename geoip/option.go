@@ -1,6 +1,6 @@
 package geoip
 
-import "slices"
+import "maps"
 
 type Option func(*Engine)
 
@@ -8,7 +8,51 @@ type Option func(*Engine)
 // NewFreeIPAPI() NewIfconfigco() NewIPapi() NewIPwho()
 func WithHandlers(iper ...IPer) Option {
 	return func(e *Engine) {
-		e.handlers = slices.Clone(iper)
+		e.handlers = append(make([]IPer, 0, len(iper)), iper...)
+	}
+}
+
+// WithCredentials set credentials for a registered provider
+// e.g. WithCredentials("gaode", Credentials{"key": "..."}) adds Gaode to the Chinese default handlers
+func WithCredentials(provider string, c Credentials) Option {
+	return func(e *Engine) {
+		if e.credentials == nil {
+			e.credentials = make(map[string]Credentials)
+		}
+		e.credentials[provider] = maps.Clone(c)
+	}
+}
+
+// WithLanguages set the fallback order of localized names
+// Info.Localized is filled for every language and Country/Region/City are rewritten
+// with the first language that has a value, e.g. WithLanguages(English, Chinese)
+func WithLanguages(langs ...Language) Option {
+	return func(e *Engine) {
+		e.languages = append([]Language(nil), langs...)
+	}
+}
+
+// WithCarrierRules set custom carrier rules, matched before the built-in rules
+// rules can be loaded with OpenCarrierRules or LoadCarrierRules
+func WithCarrierRules(rules *CarrierRules) Option {
+	return func(e *Engine) {
+		e.carriers = rules
+	}
+}
+
+// WithAddressTemplate build Info.Address with the template instead of the provider's format
+// e.g. WithAddressTemplate(MustParseAddressTemplate("auto"))
+func WithAddressTemplate(t *AddressTemplate) Option {
+	return func(e *Engine) {
+		e.address = t
+	}
+}
+
+// WithEnrichers run enrichers in order after a provider succeeds, before the result is cached
+// e.g. WithEnrichers(NewReverseDNS(nil)) sets Info.Hostname and Info.HostnameVerified
+func WithEnrichers(en ...Enricher) Option {
+	return func(e *Engine) {
+		e.enrichers = append(e.enrichers, en...)
 	}
 }
 
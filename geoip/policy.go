@@ -0,0 +1,313 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Action 策略动作
+type Action int
+
+const (
+	Allow Action = iota + 1
+	Deny
+)
+
+func (a Action) String() string {
+	switch a {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	}
+	return "unknown"
+}
+
+func parseAction(s string) (Action, bool) {
+	switch strings.ToLower(s) {
+	case "allow":
+		return Allow, true
+	case "deny":
+		return Deny, true
+	}
+	return 0, false
+}
+
+// policyFields 规则支持的字段
+var policyFields = map[string]func(c *policyCond, info *Info) bool{
+	"country": func(c *policyCond, info *Info) bool { return c.has(info.CountryCode) },
+	"region": func(c *policyCond, info *Info) bool {
+		_, suffix, _ := strings.Cut(info.SubdivisionCode, "-")
+		return c.has(info.SubdivisionCode) || c.has(suffix) || c.has(info.RegionCode) || c.has(normalizeName(info.Region))
+	},
+	"city": func(c *policyCond, info *Info) bool {
+		return c.has(info.CityCode) || c.has(normalizeName(info.City))
+	},
+	"asn": func(c *policyCond, info *Info) bool { return info.ASN > 0 && c.has(fmt.Sprint(info.ASN)) },
+	"isp": func(c *policyCond, info *Info) bool {
+		isp, org := normalizeName(info.ISP), normalizeName(info.Org)
+		for v := range c.values {
+			if strings.Contains(isp, v) || strings.Contains(org, v) {
+				return true
+			}
+		}
+		return false
+	},
+	"carrier": func(c *policyCond, info *Info) bool { return info.Carrier != nil && c.has(info.Carrier.ID) },
+	"ip": func(c *policyCond, info *Info) bool {
+		addr, err := netip.ParseAddr(info.IP)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, p := range c.prefixes {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+// policyCond 规则中的一个条件，值之间为或的关系
+type policyCond struct {
+	field    string
+	values   map[string]bool
+	prefixes []netip.Prefix
+}
+
+func (c *policyCond) has(v string) bool {
+	return v != "" && c.values[strings.ToUpper(v)]
+}
+
+// PolicyRule 一条 allow/deny 规则，所有条件都满足时匹配
+type PolicyRule struct {
+	Action Action
+	Text   string // 规则原文，如 deny asn=AS14061
+	conds  []policyCond
+}
+
+func (r *PolicyRule) match(info *Info) bool {
+	for i := range r.conds {
+		if !policyFields[r.conds[i].field](&r.conds[i], info) {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy 按国家、省/州、城市、ASN、ISP、运营商、IP 段放行或拦截的策略，参见 CompilePolicy
+type Policy struct {
+	rules   []PolicyRule
+	deflt   Action
+	private Action
+	failure Action
+}
+
+// CompilePolicy 编译策略，语句以分号或换行分隔，# 之后为注释
+//
+//	allow country=CN,HK            # 值之间为或
+//	deny country=CN region=XJ      # 条件之间为且
+//	deny asn=AS14061
+//	deny isp=digitalocean          # ISP/Org 包含该值，不区分大小写
+//	allow ip=10.0.0.0/8,192.0.2.1
+//	default deny                   # 没有规则匹配时，默认 allow
+//	private allow                  # 内网、本机地址没有匹配的 ip 规则时，默认 allow
+//	error deny                     # 查询失败时，默认 allow 即 fail-open，deny 即 fail-closed
+//
+// 规则按顺序匹配，第一条匹配的规则生效，值中不能包含空白
+// 字段有 country、region、city、asn、isp、carrier、ip，country 可以是代码或名称，region 可以是 ISO 3166-2 代码、行政区划代码或名称
+func CompilePolicy(src string) (*Policy, error) {
+	p := Policy{deflt: Allow, private: Allow, failure: Allow}
+	for n, line := range strings.Split(src, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		for stmt := range strings.SplitSeq(line, ";") {
+			fields := strings.Fields(stmt)
+			if len(fields) == 0 {
+				continue
+			}
+			if err := p.compile(fields); err != nil {
+				return nil, fmt.Errorf("policy line %d: %q: %w", n+1, strings.TrimSpace(stmt), err)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// MustCompilePolicy 同 CompilePolicy，出错时 panic
+func MustCompilePolicy(src string) *Policy {
+	p, err := CompilePolicy(src)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Policy) compile(fields []string) error {
+	keyword := strings.ToLower(fields[0])
+	switch keyword {
+	case "default", "private", "error":
+		if len(fields) != 2 {
+			return fmt.Errorf("expected %s allow|deny", keyword)
+		}
+		a, ok := parseAction(fields[1])
+		if !ok {
+			return fmt.Errorf("unknown action %q", fields[1])
+		}
+		switch keyword {
+		case "default":
+			p.deflt = a
+		case "private":
+			p.private = a
+		default:
+			p.failure = a
+		}
+		return nil
+	}
+
+	a, ok := parseAction(keyword)
+	if !ok {
+		return fmt.Errorf("unknown action %q", fields[0])
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("missing condition")
+	}
+	rule := PolicyRule{Action: a, Text: strings.Join(fields, " ")}
+	for _, f := range fields[1:] {
+		cond, err := compileCond(f)
+		if err != nil {
+			return err
+		}
+		rule.conds = append(rule.conds, cond)
+	}
+	p.rules = append(p.rules, rule)
+	return nil
+}
+
+func compileCond(s string) (policyCond, error) {
+	field, list, ok := strings.Cut(s, "=")
+	field = strings.ToLower(field)
+	if !ok || list == "" {
+		return policyCond{}, fmt.Errorf("expected field=value, got %q", s)
+	}
+	if _, ok := policyFields[field]; !ok {
+		return policyCond{}, fmt.Errorf("unknown field %q", field)
+	}
+	c := policyCond{field: field, values: make(map[string]bool)}
+	for v := range strings.SplitSeq(list, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		switch field {
+		case "country":
+			code := NormalizeCountry(v)
+			if code == "" {
+				return policyCond{}, fmt.Errorf("unknown country %q", v)
+			}
+			v = code
+		case "asn":
+			asn := parseASN(v)
+			if asn <= 0 {
+				return policyCond{}, fmt.Errorf("invalid asn %q", v)
+			}
+			v = fmt.Sprint(asn)
+		case "ip":
+			prefix, err := netip.ParsePrefix(v)
+			if err != nil {
+				addr, err := netip.ParseAddr(v)
+				if err != nil {
+					return policyCond{}, fmt.Errorf("invalid ip %q", v)
+				}
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+			if prefix.Addr().Is4In6() {
+				if prefix.Bits() < 96 {
+					return policyCond{}, fmt.Errorf("ipv4-mapped ip %q shorter than /96", v)
+				}
+				prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+			}
+			c.prefixes = append(c.prefixes, prefix.Masked())
+			continue
+		case "isp":
+			// 按子串匹配，保留 normalizeName 的小写
+			c.values[normalizeName(v)] = true
+			continue
+		case "region", "city":
+			v = normalizeName(v)
+		}
+		c.values[strings.ToUpper(v)] = true
+	}
+	if len(c.values) == 0 && len(c.prefixes) == 0 {
+		return policyCond{}, fmt.Errorf("empty values for %q", field)
+	}
+	return c, nil
+}
+
+// Decision 策略的判定结果
+type Decision struct {
+	Action Action
+	Rule   *PolicyRule // 匹配的规则，由 default/private/error 决定时为 nil
+	Reason string      // 规则原文，或 default、private、error
+	Info   *Info       // 查询结果，内网地址或查询失败时为 nil
+	Err    error       // 查询失败的原因
+}
+
+// Allowed 是否放行
+func (d Decision) Allowed() bool {
+	return d.Action == Allow
+}
+
+// Match 对查询结果应用规则
+func (p *Policy) Match(info *Info) Decision {
+	for i := range p.rules {
+		if p.rules[i].match(info) {
+			return Decision{Action: p.rules[i].Action, Rule: &p.rules[i], Reason: p.rules[i].Text, Info: info}
+		}
+	}
+	return Decision{Action: p.deflt, Reason: "default", Info: info}
+}
+
+// matchPrivate 内网地址只匹配仅包含 ip 条件的规则，没有匹配时使用 private 动作
+func (p *Policy) matchPrivate(ip string) Decision {
+	info := &Info{IP: ip}
+	for i := range p.rules {
+		r := &p.rules[i]
+		onlyIP := true
+		for _, c := range r.conds {
+			onlyIP = onlyIP && c.field == "ip"
+		}
+		if onlyIP && r.match(info) {
+			return Decision{Action: r.Action, Rule: r, Reason: r.Text}
+		}
+	}
+	return Decision{Action: p.private, Reason: "private"}
+}
+
+// Evaluate 查询 ip 并应用规则
+// 内网、本机及链路本地地址只匹配 ip 规则，否则使用 private 动作；无效 IP 与查询失败使用 error 动作
+func (p *Policy) Evaluate(ctx context.Context, e *Engine, ip string) Decision {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Decision{Action: p.failure, Reason: "error", Err: err}
+	}
+	addr = addr.Unmap()
+	if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return p.matchPrivate(ip)
+	}
+	info, err := e.Lookup(ctx, ip)
+	if err != nil {
+		if IsErrPrivateIP(err) {
+			return p.matchPrivate(ip)
+		}
+		return Decision{Action: p.failure, Reason: "error", Err: err}
+	}
+	// ip 规则按查询的地址匹配，provider 可能不返回 IP
+	if info.IP == "" {
+		c := *info
+		c.IP = ip
+		info = &c
+	}
+	return p.Match(info)
+}
@@ -0,0 +1,98 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// policyIPer 返回固定结果，用于模拟查询失败
+type policyIPer map[string]*Info
+
+func (p policyIPer) Lookup(_ context.Context, ip string) (*Info, error) {
+	if info, ok := p[ip]; ok {
+		c := *info
+		return &c, nil
+	}
+	return nil, errors.New("lookup failed")
+}
+
+func TestPolicy(t *testing.T) {
+	e := New(English, WithHandlers(policyIPer{
+		"183.95.1.1":    {IP: "183.95.1.1", Country: "China", Region: "Hubei", City: "Jingmen", ISP: "China Unicom"},
+		"1.180.1.1":     {IP: "1.180.1.1", Country: "China", Region: "Xinjiang", City: "Urumqi"},
+		"203.198.1.1":   {IP: "203.198.1.1", Country: "Hong Kong", ISP: "PCCW"},
+		"104.131.1.1":   {IP: "104.131.1.1", Country: "United States", ASN: 14061, ISP: "DigitalOcean, LLC"},
+		"8.8.8.8":       {IP: "8.8.8.8", Country: "United States", ASN: 15169, Org: "Google LLC"},
+		"198.51.100.10": {Country: "Japan"},
+	}))
+	p, err := CompilePolicy(`
+		deny asn=AS14061; deny region=XJ   # 注释
+		deny isp=google
+		allow country=CN,hongkong
+		allow country=JP ip=198.51.100.0/24
+		allow ip=10.1.0.0/16
+		default deny
+		private deny
+		error deny
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ip     string
+		action Action
+		reason string
+	}{
+		{"183.95.1.1", Allow, "allow country=CN,hongkong"},
+		{"1.180.1.1", Deny, "deny region=XJ"},
+		{"203.198.1.1", Allow, "allow country=CN,hongkong"},
+		{"104.131.1.1", Deny, "deny asn=AS14061"},
+		{"8.8.8.8", Deny, "deny isp=google"},
+		{"198.51.100.10", Allow, "allow country=JP ip=198.51.100.0/24"},
+		{"10.1.2.3", Allow, "allow ip=10.1.0.0/16"},
+		{"10.2.2.3", Deny, "private"},
+		{"127.0.0.1", Deny, "private"},
+		{"9.9.9.9", Deny, "error"},
+		{"nope", Deny, "error"},
+	}
+	for _, c := range cases {
+		d := p.Evaluate(context.Background(), e, c.ip)
+		if d.Action != c.action || d.Reason != c.reason {
+			t.Fatalf("%s not match, got: %s %s %v", c.ip, d.Action, d.Reason, d.Err)
+		}
+		if matched := strings.Contains(c.reason, "="); (d.Rule != nil) != matched {
+			t.Fatalf("%s rule not match, got: %+v", c.ip, d.Rule)
+		}
+	}
+
+	// 默认 fail-open
+	p = MustCompilePolicy("deny country=US")
+	if d := p.Evaluate(context.Background(), e, "9.9.9.9"); !d.Allowed() || d.Err == nil {
+		t.Fatalf("fail-open not match, got: %+v", d)
+	}
+	if d := p.Match(&Info{CountryCode: "DE"}); !d.Allowed() || d.Reason != "default" {
+		t.Fatalf("default not match, got: %+v", d)
+	}
+}
+
+func TestCompilePolicyError(t *testing.T) {
+	for _, src := range []string{
+		"block country=CN",
+		"allow",
+		"allow country",
+		"allow planet=earth",
+		"allow country=Atlantis",
+		"deny asn=ASX",
+		"deny ip=300.0.0.1",
+		"deny ip=::ffff:0:0/64",
+		"default maybe",
+		"allow country=,",
+	} {
+		if _, err := CompilePolicy(src); err == nil {
+			t.Fatalf("%q: expected error", src)
+		}
+	}
+}
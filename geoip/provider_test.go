@@ -0,0 +1,397 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newFixtureServer 返回 testdata 中录制的响应，check 用于校验请求
+func newFixtureServer(t *testing.T, fixture string, check func(*http.Request)) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestIPinfo(t *testing.T) {
+	s := newFixtureServer(t, "ipinfo.io.json", func(r *http.Request) {
+		if r.URL.Path != "/8.8.8.8/json" {
+			t.Errorf("path not match, got: %s", r.URL.Path)
+		}
+		if v := r.Header.Get("Authorization"); v != "Bearer token" {
+			t.Errorf("authorization not match, got: %s", v)
+		}
+	})
+	p := NewIPinfo("token").(*IPinfo)
+	p.link = s.URL + "/"
+
+	info, err := p.Lookup(context.Background(), "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CountryCode != "US" || info.Region != "California" || info.City != "Mountain View" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+	if info.Latitude != 37.4056 || info.Longitude != -122.0775 {
+		t.Fatalf("loc not match, got: %f,%f", info.Latitude, info.Longitude)
+	}
+	if info.ASN != 15169 || info.Org != "Google LLC" || info.Postal != "94043" || info.Timezone != "America/Los_Angeles" {
+		t.Fatalf("network not match, got: %+v", info)
+	}
+	if info.Privacy == nil || !info.Privacy.Hosting {
+		t.Fatalf("privacy not match, got: %+v", info.Privacy)
+	}
+	if info.Company == nil || info.Company.Domain != "google.com" {
+		t.Fatalf("company not match, got: %+v", info.Company)
+	}
+	if info.Abuse == nil || info.Abuse.Email != "network-abuse@google.com" {
+		t.Fatalf("abuse not match, got: %+v", info.Abuse)
+	}
+}
+
+func TestIPinfoQuotaExceeded(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"status":429,"error":{"title":"Rate limit exceeded"}}`))
+	}))
+	defer s.Close()
+	p := NewIPinfo("").(*IPinfo)
+	p.link = s.URL + "/"
+
+	_, err := p.Lookup(context.Background(), "8.8.8.8")
+	if !IsErrQuotaExceeded(err) {
+		t.Fatalf("expected ErrQuotaExceeded, got: %v", err)
+	}
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusTooManyRequests || se.RetryAfter != time.Minute {
+		t.Fatalf("status error not match, got: %+v", se)
+	}
+}
+
+func TestParseASN(t *testing.T) {
+	for s, expected := range map[string]int{
+		"AS4837":                    4837,
+		"4837":                      4837,
+		"as15169":                   15169,
+		"AS199524 G-Core Labs S.A.": 199524,
+		"":                          0,
+	} {
+		if v := parseASN(s); v != expected {
+			t.Fatalf("parse %q not match, got: %d", s, v)
+		}
+	}
+}
+
+func TestIPGeolocation(t *testing.T) {
+	s := newFixtureServer(t, "ipgeolocation.io.json", func(r *http.Request) {
+		if q := r.URL.Query(); q.Get("apiKey") != "key" || q.Get("ip") != "8.8.8.8" {
+			t.Errorf("query not match, got: %s", r.URL.RawQuery)
+		}
+	})
+	p := NewIPGeolocation("key").(*IPGeolocation)
+	p.link = s.URL
+
+	info, err := p.Lookup(context.Background(), "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Country != "United States" || info.CountryCode != "US" || info.Region != "California" || info.RegionCode != "CA" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+	if info.Latitude != 37.4224 || info.Longitude != -122.08421 || info.Timezone != "America/Los_Angeles" {
+		t.Fatalf("coordinate not match, got: %+v", info)
+	}
+	if info.ASN != 15169 || info.ISP != "Google LLC" {
+		t.Fatalf("network not match, got: %+v", info)
+	}
+}
+
+func TestIPdata(t *testing.T) {
+	s := newFixtureServer(t, "ipdata.co.json", func(r *http.Request) {
+		if r.URL.Path != "/8.8.8.8" || r.URL.Query().Get("api-key") != "key" {
+			t.Errorf("request not match, got: %s", r.URL)
+		}
+	})
+	p := NewIPdata("key").(*IPdata)
+	p.link = s.URL + "/"
+
+	info, err := p.Lookup(context.Background(), "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Country != "United States" || info.RegionCode != "CA" || info.City != "Mountain View" || info.Postal != "94035" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+	if info.ASN != 15169 || info.Org != "Google LLC" || info.Timezone != "America/Los_Angeles" {
+		t.Fatalf("network not match, got: %+v", info)
+	}
+	if info.Privacy == nil || !info.Privacy.Hosting || info.Privacy.VPN {
+		t.Fatalf("privacy not match, got: %+v", info.Privacy)
+	}
+}
+
+func TestIP2Location(t *testing.T) {
+	s := newFixtureServer(t, "ip2location.io.json", func(r *http.Request) {
+		if q := r.URL.Query(); q.Get("key") != "key" || q.Get("ip") != "8.8.8.8" {
+			t.Errorf("query not match, got: %s", r.URL.RawQuery)
+		}
+	})
+	p := NewIP2Location("key").(*IP2Location)
+	p.link = s.URL + "/"
+
+	info, err := p.Lookup(context.Background(), "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CountryCode != "US" || info.Region != "California" || info.City != "Mountain View" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+	if info.ASN != 15169 || info.ISP != "Google LLC" || info.Privacy != nil {
+		t.Fatalf("network not match, got: %+v", info)
+	}
+
+	e := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"error_code":10000,"error_message":"Invalid API key or insufficient credit."}}`))
+	}))
+	defer e.Close()
+	p.link = e.URL + "/"
+	if _, err := p.Lookup(context.Background(), "8.8.8.8"); !IsErrUnauthorized(err) {
+		t.Fatalf("expected ErrUnauthorized, got: %v", err)
+	}
+
+	// 无法识别的错误码保留状态码的归类
+	q := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"error_code":10099,"error_message":"Too many requests."}}`))
+	}))
+	defer q.Close()
+	p.link = q.URL + "/"
+	_, err = p.Lookup(context.Background(), "8.8.8.8")
+	var se *StatusError
+	if !IsErrQuotaExceeded(err) || !errors.As(err, &se) || se.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected ErrQuotaExceeded, got: %v", err)
+	}
+}
+
+func TestMaxMind(t *testing.T) {
+	s := newFixtureServer(t, "maxmind.com.json", func(r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "42" || pass != "license" {
+			t.Errorf("basic auth not match, got: %s %s", user, pass)
+		}
+		if r.URL.Path != "/geoip/v2.1/city/8.8.8.8" {
+			t.Errorf("path not match, got: %s", r.URL.Path)
+		}
+	})
+	p := NewMaxMind("42", "license", "").(*MaxMind)
+	p.link = s.URL + "/geoip/v2.1/city/"
+
+	info, err := p.Lookup(context.Background(), "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Country != "United States" || info.CountryCode != "US" || info.Region != "California" || info.RegionCode != "CA" || info.City != "Mountain View" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+	if info.AccuracyRadius != 1000 {
+		t.Fatalf("accuracy radius not match, got: %d", info.AccuracyRadius)
+	}
+	if info.ASN != 15169 || info.ISP != "Google" || info.Privacy == nil || !info.Privacy.Hosting {
+		t.Fatalf("network not match, got: %+v", info)
+	}
+	if n := info.Localized[Chinese]; n.Country != "美国" || n.City != "芒廷维尤" {
+		t.Fatalf("localized not match, got: %+v", info.Localized)
+	}
+
+	e := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":"IP_ADDRESS_RESERVED","error":"The value 10.0.0.1 belongs to a reserved or private range."}`))
+	}))
+	defer e.Close()
+	p.link = e.URL + "/"
+	if _, err := p.Lookup(context.Background(), "10.0.0.1"); !IsErrPrivateIP(err) {
+		t.Fatalf("expected ErrPrivateIP, got: %v", err)
+	}
+
+	q := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"code":"SOMETHING_NEW","error":"Slow down."}`))
+	}))
+	defer q.Close()
+	p.link = q.URL + "/"
+	_, err = p.Lookup(context.Background(), "8.8.8.8")
+	var se *StatusError
+	if !IsErrQuotaExceeded(err) || !errors.As(err, &se) || se.RetryAfter != 30*time.Second {
+		t.Fatalf("expected ErrQuotaExceeded, got: %v", err)
+	}
+}
+
+func TestBaidu(t *testing.T) {
+	s := newFixtureServer(t, "map.baidu.com.json", func(r *http.Request) {
+		if r.URL.Path != "/location/ip" {
+			t.Errorf("path not match, got: %s", r.URL.Path)
+		}
+		if sn := r.URL.Query().Get("sn"); sn != "09a781ba9a12c2599b6e02adf75a60c5" {
+			t.Errorf("sn not match, got: %s", sn)
+		}
+	})
+	p := NewBaidu("ak", "sk").(*Baidu)
+	p.link = s.URL
+
+	info, err := p.Lookup(context.Background(), "183.95.255.255")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Region != "湖北省" || info.RegionCode != "420000" || info.City != "荆门市" || info.CityCode != "420800" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+	if info.ISP != "UNICOM" || info.Address != "湖北省荆门市 UNICOM" {
+		t.Fatalf("isp not match, got: %+v", info)
+	}
+	if info.Latitude == 0 || info.Latitude == 31.03546 {
+		t.Fatalf("gcj02 coordinate not converted, got: %f", info.Latitude)
+	}
+
+	for status, target := range map[int]error{101: ErrUnauthorized, 102: ErrUnauthorized, 240: ErrUnauthorized, 302: ErrQuotaExceeded, 1: ErrNotFound} {
+		b := baiduInfo{Status: status, Message: "error"}
+		if err := b.toError(); !errors.Is(err, target) {
+			t.Fatalf("status %d not match, got: %v", status, err)
+		}
+	}
+}
+
+func TestTencent(t *testing.T) {
+	s := newFixtureServer(t, "lbs.qq.com.json", func(r *http.Request) {
+		if sig := r.URL.Query().Get("sig"); sig != "677de204c82cdfd72ef93be229aeed57" {
+			t.Errorf("sig not match, got: %s", sig)
+		}
+	})
+	p := NewTencent("key", "sk").(*Tencent)
+	p.link = s.URL
+
+	info, err := p.Lookup(context.Background(), "183.95.255.255")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Country != "中国" || info.RegionCode != "420000" || info.CityCode != "420800" || info.Address != "湖北省荆门市东宝区" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+
+	e := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":121,"message":"此key每日调用量已达到上限"}`))
+	}))
+	defer e.Close()
+	p.link = e.URL
+	if _, err := p.Lookup(context.Background(), "183.95.255.255"); !IsErrQuotaExceeded(err) {
+		t.Fatalf("expected ErrQuotaExceeded, got: %v", err)
+	}
+}
+
+func TestSplitAdcode(t *testing.T) {
+	for adcode, expected := range map[string][2]string{
+		"420802": {"420000", "420800"},
+		"420800": {"420000", "420800"},
+		"110105": {"110000", "110000"},
+		"500000": {"500000", "500000"},
+		"429004": {"420000", "429004"},
+		"-1":     {"", ""},
+	} {
+		region, city := splitAdcode(adcode)
+		if region != expected[0] || city != expected[1] {
+			t.Fatalf("split %s not match, got: %s %s", adcode, region, city)
+		}
+	}
+}
+
+func TestChineseDefaultHandlers(t *testing.T) {
+	t.Setenv("NETPULSE_GAODE_KEY", "")
+	t.Setenv("NETPULSE_BAIDU_AK", "")
+	t.Setenv("NETPULSE_TENCENT_KEY", "")
+	if e := New(Chinese); len(e.handlers) != 1 {
+		t.Fatalf("handlers not match, got: %d", len(e.handlers))
+	}
+
+	t.Setenv("NETPULSE_GAODE_KEY", "k")
+	e := New(Chinese, WithCredentials("tencent", Credentials{"key": "t"}))
+	if len(e.handlers) != 3 {
+		t.Fatalf("handlers not match, got: %d", len(e.handlers))
+	}
+	if _, ok := e.handlers[1].(*Gaode); !ok {
+		t.Fatalf("expected gaode, got: %T", e.handlers[1])
+	}
+	if _, ok := e.handlers[2].(*Tencent); !ok {
+		t.Fatalf("expected tencent, got: %T", e.handlers[2])
+	}
+}
+
+func TestGaode(t *testing.T) {
+	s := newFixtureServer(t, "gaode.com.json", func(r *http.Request) {
+		if q := r.URL.Query(); q.Get("key") != "key" || q.Get("ip") != "114.247.50.2" {
+			t.Errorf("query not match, got: %s", r.URL.RawQuery)
+		}
+	})
+	p := NewGaode("key").(*Gaode)
+	p.link = s.URL
+
+	info, err := p.Lookup(context.Background(), "114.247.50.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.IP != "114.247.50.2" || info.Region != "北京市" || info.RegionCode != "110000" || info.CityCode != "110000" {
+		t.Fatalf("location not match, got: %+v", info)
+	}
+	if info.Bounds == nil {
+		t.Fatal("bounds not parsed")
+	}
+	b := info.Bounds
+	if b.MinLatitude >= b.MaxLatitude || b.MinLongitude >= b.MaxLongitude {
+		t.Fatalf("bounds not match, got: %+v", b)
+	}
+	// GCJ-02 偏移在北京约为 0.001~0.006 度
+	if b.MinLongitude > 116.0119343 || b.MinLongitude < 116.0 || b.MaxLatitude > 40.2164962 || b.MaxLatitude < 40.2 {
+		t.Fatalf("bounds not converted to wgs84, got: %+v", b)
+	}
+	if lat, lon := b.Center(); info.Latitude != lat || info.Longitude != lon || lat < 39.9 || lat > 40 {
+		t.Fatalf("centroid not match, got: %f,%f", info.Latitude, info.Longitude)
+	}
+}
+
+func TestGaodeNotFound(t *testing.T) {
+	s := newFixtureServer(t, "gaode.com.foreign.json", nil)
+	p := NewGaode("key").(*Gaode)
+	p.link = s.URL
+
+	_, err := p.Lookup(context.Background(), "8.8.8.8")
+	var nf *NotFoundError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &nf) || nf.IP != "8.8.8.8" {
+		t.Fatalf("expected NotFoundError, got: %v", err)
+	}
+
+	e := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"0","info":"INVALID_PARAMS","infocode":"20000"}`))
+	}))
+	defer e.Close()
+	p.link = e.URL
+	if _, err := p.Lookup(context.Background(), "1.2.3"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package geoip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+var _ IPer = (*QQWry)(nil)
+
+// qqwry.dat 文件结构
+//
+//	文件头 8 字节: 第一条索引偏移(4) + 最后一条索引偏移(4)，小端序
+//	索引区 每条 7 字节: 起始 IP(4) + 记录偏移(3)
+//	记录区 结束 IP(4) + 国家/地区信息
+//
+// 国家/地区信息有三种存储方式
+//
+//	0x01 + 偏移(3): 国家与地区均重定向到偏移处
+//	0x02 + 偏移(3): 仅国家重定向，地区紧跟在 4 字节之后
+//	其它: 以 \0 结尾的 GBK 字符串，国家之后紧跟地区
+//
+// 地区同样可以通过 0x01/0x02 + 偏移(3) 重定向
+const (
+	qqwryIndexLen      = 7
+	qqwryRedirectMode1 = 0x01
+	qqwryRedirectMode2 = 0x02
+)
+
+// QQWry 纯真 IP 库(qqwry.dat)离线查询，仅支持 IPv4
+// 加密的 czdb 格式需要授权密钥，暂不支持
+type QQWry struct {
+	data       []byte
+	indexStart uint32
+	indexEnd   uint32
+}
+
+// OpenQQWry 读取 qqwry.dat 文件
+func OpenQQWry(path string) (*QQWry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadQQWry(data)
+}
+
+// LoadQQWry 解析 qqwry.dat 内容，data 在解析后由 QQWry 持有，调用方不应再修改
+func LoadQQWry(data []byte) (*QQWry, error) {
+	if len(data) < 8 {
+		return nil, errors.New("qqwry: file too short")
+	}
+	q := QQWry{
+		data:       data,
+		indexStart: binary.LittleEndian.Uint32(data[0:4]),
+		indexEnd:   binary.LittleEndian.Uint32(data[4:8]),
+	}
+	if q.indexStart < 8 || q.indexEnd < q.indexStart ||
+		int(q.indexEnd)+qqwryIndexLen > len(data) || (q.indexEnd-q.indexStart)%qqwryIndexLen != 0 {
+		return nil, errors.New("qqwry: invalid index header")
+	}
+	return &q, nil
+}
+
+// Len 返回记录数量
+func (q *QQWry) Len() int {
+	return int((q.indexEnd-q.indexStart)/qqwryIndexLen) + 1
+}
+
+// Version 返回数据版本，通常为最后一条记录的地区，如 "纯真网络2024年01月01日IP数据"
+func (q *QQWry) Version() string {
+	_, area, err := q.record(q.indexEnd)
+	if err != nil {
+		return ""
+	}
+	return area
+}
+
+// Lookup implements IPer.
+func (q *QQWry) Lookup(_ context.Context, ip string) (*Info, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ip: %w", err)
+	}
+	addr = addr.Unmap()
+	if !addr.Is4() {
+		return nil, &NotFoundError{Provider: "qqwry", IP: ip, Reason: "ipv6 is not supported"}
+	}
+	v4 := addr.As4()
+	target := binary.BigEndian.Uint32(v4[:])
+
+	index, ok := q.search(target)
+	if !ok {
+		return nil, &NotFoundError{Provider: "qqwry", IP: ip}
+	}
+	country, area, err := q.record(index)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(country, "局域网") || strings.Contains(country, "保留地址") || strings.Contains(country, "本机地址") {
+		return nil, &NotFoundError{Provider: "qqwry", IP: ip, Reason: country}
+	}
+	return qqwryToInfo(ip, country, area), nil
+}
+
+// search 二分查找起始 IP 不大于 target 的索引，并校验结束 IP
+func (q *QQWry) search(target uint32) (uint32, bool) {
+	lo, hi := 0, q.Len()-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if q.read32(q.indexStart+uint32(mid)*qqwryIndexLen) <= target {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	index := q.indexStart + uint32(lo)*qqwryIndexLen
+	if q.read32(index) > target {
+		return 0, false
+	}
+	off := q.read24(index + 4)
+	if int(off)+4 > len(q.data) || q.read32(off) < target {
+		return 0, false
+	}
+	return index, true
+}
+
+// record 读取索引对应记录的国家与地区
+func (q *QQWry) record(index uint32) (country, area string, err error) {
+	off := q.read24(index+4) + 4
+	if int(off) >= len(q.data) {
+		return "", "", errors.New("qqwry: record offset out of range")
+	}
+
+	var areaOff uint32
+	switch q.data[off] {
+	case qqwryRedirectMode1:
+		off = q.read24(off + 1)
+		if int(off) >= len(q.data) {
+			return "", "", errors.New("qqwry: redirect offset out of range")
+		}
+		if q.data[off] == qqwryRedirectMode2 {
+			country, _ = q.readString(q.read24(off + 1))
+			areaOff = off + 4
+		} else {
+			var n uint32
+			country, n = q.readString(off)
+			areaOff = off + n + 1
+		}
+	case qqwryRedirectMode2:
+		country, _ = q.readString(q.read24(off + 1))
+		areaOff = off + 4
+	default:
+		var n uint32
+		country, n = q.readString(off)
+		areaOff = off + n + 1
+	}
+	return country, q.readArea(areaOff), nil
+}
+
+func (q *QQWry) readArea(off uint32) string {
+	if int(off) >= len(q.data) {
+		return ""
+	}
+	if mode := q.data[off]; mode == qqwryRedirectMode1 || mode == qqwryRedirectMode2 {
+		p := q.read24(off + 1)
+		if p == 0 {
+			return ""
+		}
+		off = p
+	}
+	s, _ := q.readString(off)
+	return s
+}
+
+// readString 读取以 \0 结尾的 GBK 字符串，返回解码后的字符串与原始字节长度
+func (q *QQWry) readString(off uint32) (string, uint32) {
+	if int(off) >= len(q.data) {
+		return "", 0
+	}
+	raw := q.data[off:]
+	if i := bytes.IndexByte(raw, 0); i >= 0 {
+		raw = raw[:i]
+	}
+	s, err := simplifiedchinese.GBK.NewDecoder().Bytes(raw)
+	if err != nil {
+		return string(raw), uint32(len(raw))
+	}
+	return strings.TrimSpace(string(s)), uint32(len(raw))
+}
+
+func (q *QQWry) read32(off uint32) uint32 {
+	if int(off)+4 > len(q.data) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(q.data[off:])
+}
+
+func (q *QQWry) read24(off uint32) uint32 {
+	if int(off)+3 > len(q.data) {
+		return 0
+	}
+	b := q.data[off:]
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
+// chineseProvinces 省级行政区简称，用于拆分 "湖北省荆门市" 格式的地址
+var chineseProvinces = []string{
+	"北京", "天津", "河北", "山西", "内蒙古", "辽宁", "吉林", "黑龙江",
+	"上海", "江苏", "浙江", "安徽", "福建", "江西", "山东", "河南",
+	"湖北", "湖南", "广东", "广西", "海南", "重庆", "四川", "贵州",
+	"云南", "西藏", "陕西", "甘肃", "青海", "宁夏", "新疆", "香港",
+	"澳门", "台湾",
+}
+
+// provinceSuffixes 省级行政区全称后缀，按长度降序
+var provinceSuffixes = []string{"维吾尔自治区", "壮族自治区", "回族自治区", "特别行政区", "自治区", "省", "市"}
+
+// splitChineseLocation 将 "湖北省荆门市"、"中国–湖北–荆门" 等格式拆分为国家/省/市
+func splitChineseLocation(s string) (country, region, city string) {
+	// 新版纯真数据使用 "–" 分隔
+	if strings.Contains(s, "–") {
+		parts := strings.Split(s, "–")
+		country = parts[0]
+		if len(parts) > 1 {
+			region = parts[1]
+		}
+		if len(parts) > 2 {
+			city = parts[2]
+		}
+		if country == "中国" {
+			country = chineseCountry(region)
+		}
+		return
+	}
+
+	for _, p := range chineseProvinces {
+		if !strings.HasPrefix(s, p) {
+			continue
+		}
+		region = p
+		rest := s[len(p):]
+		for _, suffix := range provinceSuffixes {
+			if strings.HasPrefix(rest, suffix) {
+				region += suffix
+				rest = rest[len(suffix):]
+				break
+			}
+		}
+		city = rest
+		if city == "" && strings.HasSuffix(region, "市") {
+			city = region
+		}
+		return chineseCountry(region), region, city
+	}
+	return s, "", ""
+}
+
+// chineseCountry 港澳台作为独立的国家/地区返回，与 provinceEntry.countryCode 一致
+func chineseCountry(region string) string {
+	for _, p := range []string{"香港", "澳门", "台湾"} {
+		if strings.HasPrefix(region, p) {
+			return p
+		}
+	}
+	return "中国"
+}
+
+func qqwryToInfo(ip, country, area string) *Info {
+	if strings.Contains(area, "CZ88.NET") {
+		area = ""
+	}
+	c, region, city := splitChineseLocation(country)
+	info := Info{
+		IP:      ip,
+		Country: c,
+		Region:  region,
+		City:    city,
+		ISP:     area,
+	}
+	if region != "" {
+		loc := region
+		if city != region {
+			loc += city
+		}
+		info.Address = joinNonEmpty(" ", loc, area)
+	} else {
+		info.Address = joinNonEmpty(" ", country, area)
+	}
+	return &info
+}
@@ -0,0 +1,172 @@
+package geoip
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// qqwryWriter 构造测试用的 qqwry.dat
+type qqwryWriter struct {
+	t     *testing.T
+	data  []byte
+	index []byte
+}
+
+func newQQWryWriter(t *testing.T) *qqwryWriter {
+	return &qqwryWriter{t: t, data: make([]byte, 8)}
+}
+
+func (w *qqwryWriter) str(s string) uint32 {
+	off := uint32(len(w.data))
+	b, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		w.t.Fatal(err)
+	}
+	w.data = append(append(w.data, b...), 0)
+	return off
+}
+
+func (w *qqwryWriter) u24(v uint32) {
+	w.data = append(w.data, byte(v), byte(v>>8), byte(v>>16))
+}
+
+func (w *qqwryWriter) ipv4(s string) uint32 {
+	a := netip.MustParseAddr(s).As4()
+	return binary.BigEndian.Uint32(a[:])
+}
+
+// begin 写入索引与记录的结束 IP
+func (w *qqwryWriter) begin(start, end string) {
+	off := uint32(len(w.data))
+	w.index = binary.LittleEndian.AppendUint32(w.index, w.ipv4(start))
+	w.index = append(w.index, byte(off), byte(off>>8), byte(off>>16))
+	w.data = binary.LittleEndian.AppendUint32(w.data, w.ipv4(end))
+}
+
+func (w *qqwryWriter) bytes() []byte {
+	first := uint32(len(w.data))
+	last := first + uint32(len(w.index)) - qqwryIndexLen
+	binary.LittleEndian.PutUint32(w.data[0:4], first)
+	binary.LittleEndian.PutUint32(w.data[4:8], last)
+	return append(w.data, w.index...)
+}
+
+func buildQQWry(t *testing.T) []byte {
+	w := newQQWryWriter(t)
+
+	// 被重定向引用的共享字符串
+	shared := w.str("湖北省荆门市")
+	w.str("联通")
+	country := w.str("广西桂林市")
+	isp := w.str("电信")
+
+	// 普通记录
+	w.begin("1.0.0.0", "1.0.0.255")
+	w.str("澳大利亚")
+	w.str(" CZ88.NET")
+
+	w.begin("1.32.192.0", "1.32.192.255")
+	w.str("香港特别行政区")
+	w.str("电讯盈科")
+
+	// 模式 1: 国家与地区均重定向
+	w.begin("183.95.0.0", "183.95.255.255")
+	w.data = append(w.data, qqwryRedirectMode1)
+	w.u24(shared)
+
+	// 模式 2: 仅国家重定向，地区同样以模式 2 重定向
+	w.begin("202.103.0.0", "202.103.63.255")
+	w.data = append(w.data, qqwryRedirectMode2)
+	w.u24(country)
+	w.data = append(w.data, qqwryRedirectMode2)
+	w.u24(isp)
+
+	// 新版 "–" 分隔格式
+	w.begin("210.0.0.0", "210.0.0.255")
+	w.str("中国–北京–北京")
+	w.str("移动")
+
+	w.begin("255.255.255.0", "255.255.255.255")
+	w.str("IANA保留地址")
+	w.str("纯真网络2024年01月01日IP数据")
+	return w.bytes()
+}
+
+func TestQQWry(t *testing.T) {
+	db, err := LoadQQWry(buildQQWry(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 6 {
+		t.Fatalf("len not match, got: %d", db.Len())
+	}
+	if v := db.Version(); v != "纯真网络2024年01月01日IP数据" {
+		t.Fatalf("version not match, got: %s", v)
+	}
+
+	cases := []struct {
+		ip                         string
+		country, region, city, isp string
+		address                    string
+	}{
+		{"1.0.0.1", "澳大利亚", "", "", "", "澳大利亚"},
+		{"1.32.192.1", "香港", "香港特别行政区", "", "电讯盈科", "香港特别行政区 电讯盈科"},
+		{"183.95.1.2", "中国", "湖北省", "荆门市", "联通", "湖北省荆门市 联通"},
+		{"202.103.10.1", "中国", "广西", "桂林市", "电信", "广西桂林市 电信"},
+		{"210.0.0.1", "中国", "北京", "北京", "移动", "北京 移动"},
+		{"::ffff:183.95.255.255", "中国", "湖北省", "荆门市", "联通", "湖北省荆门市 联通"},
+	}
+	for _, c := range cases {
+		info, err := db.Lookup(context.Background(), c.ip)
+		if err != nil {
+			t.Fatalf("%s: %v", c.ip, err)
+		}
+		if info.IP != c.ip || info.Country != c.country || info.Region != c.region ||
+			info.City != c.city || info.ISP != c.isp || info.Address != c.address {
+			t.Fatalf("%s: info not match, got: %+v", c.ip, info)
+		}
+	}
+
+	// 港澳台与其他服务商一样作为独立的国家/地区
+	info, err := db.Lookup(context.Background(), "1.32.192.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Normalize(Chinese) || info.CountryCode != "HK" {
+		t.Fatalf("country code not match, got: %+v", info)
+	}
+
+	for _, ip := range []string{"0.0.0.1", "183.96.0.0", "255.255.255.1", "2001:db8::1"} {
+		if _, err := db.Lookup(context.Background(), ip); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("%s: expected not found, got: %v", ip, err)
+		}
+	}
+
+	if _, err := LoadQQWry([]byte{1, 2, 3}); err == nil {
+		t.Fatal("expected error for short file")
+	}
+}
+
+func TestSplitChineseLocation(t *testing.T) {
+	cases := []struct{ in, country, region, city string }{
+		{"北京市", "中国", "北京市", "北京市"},
+		{"北京市海淀区", "中国", "北京市", "海淀区"},
+		{"内蒙古呼和浩特市", "中国", "内蒙古", "呼和浩特市"},
+		{"新疆维吾尔自治区乌鲁木齐市", "中国", "新疆维吾尔自治区", "乌鲁木齐市"},
+		{"美国", "美国", "", ""},
+		{"中国–湖北–荆门", "中国", "湖北", "荆门"},
+		{"台湾省台北市", "台湾", "台湾省", "台北市"},
+		{"中国–澳门–澳门", "澳门", "澳门", "澳门"},
+	}
+	for _, c := range cases {
+		country, region, city := splitChineseLocation(c.in)
+		if country != c.country || region != c.region || city != c.city {
+			t.Fatalf("%s not match, got: %s/%s/%s", c.in, country, region, city)
+		}
+	}
+}
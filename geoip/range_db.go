@@ -0,0 +1,81 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+)
+
+var _ IPer = (*RangeDB)(nil)
+
+// ipRange 一段连续 IP [start, end] 对应的信息
+type ipRange struct {
+	start netip.Addr
+	end   netip.Addr
+	info  *Info
+	line  int // 来源行号，用于错误提示
+}
+
+// RangeDB 离线 IP 段数据库，按起始 IP 排序，通过二分查找定位
+// 由 LoadCSV/OpenCSV 等函数创建，创建后只读，可以并发查询
+type RangeDB struct {
+	ranges []ipRange
+}
+
+// newRangeDB 排序并校验 IP 段，起止地址族不一致、起始大于结束或存在重叠时返回错误
+func newRangeDB(ranges []ipRange) (*RangeDB, error) {
+	for _, r := range ranges {
+		if r.start.Is4() != r.end.Is4() {
+			return nil, fmt.Errorf("line %d: %s-%s mixes ipv4 and ipv6", r.line, r.start, r.end)
+		}
+		if r.start.Compare(r.end) > 0 {
+			return nil, fmt.Errorf("line %d: start %s is greater than end %s", r.line, r.start, r.end)
+		}
+	}
+	slices.SortFunc(ranges, func(a, b ipRange) int { return a.start.Compare(b.start) })
+	for i := 1; i < len(ranges); i++ {
+		prev, cur := ranges[i-1], ranges[i]
+		if cur.start.Compare(prev.end) <= 0 {
+			return nil, fmt.Errorf("line %d: %s-%s overlaps line %d: %s-%s",
+				cur.line, cur.start, cur.end, prev.line, prev.start, prev.end)
+		}
+	}
+	return &RangeDB{ranges: ranges}, nil
+}
+
+// Len 返回 IP 段数量
+func (db *RangeDB) Len() int {
+	return len(db.ranges)
+}
+
+// Lookup implements IPer.
+func (db *RangeDB) Lookup(_ context.Context, ip string) (*Info, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ip: %w", err)
+	}
+	r, ok := db.find(addr.Unmap())
+	if !ok {
+		return nil, &NotFoundError{Provider: "rangedb", IP: ip}
+	}
+	info := *r.info
+	info.IP = ip
+	return &info, nil
+}
+
+func (db *RangeDB) find(addr netip.Addr) (*ipRange, bool) {
+	// 第一个起始地址大于 addr 的位置，其前一个即为候选段
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return db.ranges[i].start.Compare(addr) > 0
+	})
+	if i == 0 {
+		return nil, false
+	}
+	r := &db.ranges[i-1]
+	if addr.Compare(r.end) > 0 {
+		return nil, false
+	}
+	return r, true
+}
@@ -0,0 +1,98 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadCSV(t *testing.T) {
+	const data = `start,end,country,region,city,isp
+1.0.0.0,1.0.0.255,Australia,Queensland,Brisbane,APNIC
+183.95.0.0,183.95.255.255,中国,湖北省,荆门市,联通
+2001:db8::,2001:db8::ffff,Example,,,Documentation
+10.0.0.0,10.255.255.255,-,-,-,-
+`
+	db, err := LoadCSV(strings.NewReader(data), CSVOptions{Header: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 {
+		t.Fatalf("len not match, got: %d", db.Len())
+	}
+
+	info, err := db.Lookup(context.Background(), "183.95.1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.IP != "183.95.1.2" || info.Region != "湖北省" || info.City != "荆门市" || info.ISP != "联通" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+	if info.Address != "中国 湖北省 荆门市 联通" {
+		t.Fatalf("address not match, got: %s", info.Address)
+	}
+
+	if info, err := db.Lookup(context.Background(), "2001:db8::1"); err != nil || info.ISP != "Documentation" {
+		t.Fatalf("ipv6 lookup failed: %v %+v", err, info)
+	}
+	if info, err := db.Lookup(context.Background(), "::ffff:1.0.0.1"); err != nil || info.City != "Brisbane" {
+		t.Fatalf("mapped lookup failed: %v %+v", err, info)
+	}
+	for _, ip := range []string{"0.255.255.255", "1.0.1.0", "10.1.1.1", "2001:db8::1:0"} {
+		if _, err := db.Lookup(context.Background(), ip); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("%s expected ErrNotFound, got: %v", ip, err)
+		}
+	}
+}
+
+func TestLoadCSVIP2Location(t *testing.T) {
+	// IP2Location LITE DB11 IPv6 格式，IPv4 段为 ::ffff:0:0/96 对应的十进制数字
+	const data = `"0","281470681743359","-","-","-","-","0.000000","0.000000","-","-"
+"281473758265088","281473758265343","CN","China","Hubei","Jingmen","31.033330","112.200000","448000","+08:00"
+"42541956101370907050197289607612071936","42541956101370907050197289607612137471","US","United States of America","California","Mountain View","37.405992","-122.078515","94043","-07:00"
+`
+	db, err := LoadCSV(strings.NewReader(data), CSVOptions{Columns: CSVIP2LocationLite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 281473758265088 = ::ffff:183.95.255.0
+	info, err := db.Lookup(context.Background(), "183.95.255.255")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CountryCode != "CN" || info.City != "Jingmen" || info.Latitude != 31.03333 || info.Postal != "448000" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+	if info, err := db.Lookup(context.Background(), "2001:4860::1"); err != nil || info.City != "Mountain View" {
+		t.Fatalf("ipv6 lookup failed: %v %+v", err, info)
+	}
+}
+
+func TestLoadCSVValidate(t *testing.T) {
+	cases := map[string]string{
+		"overlap":  "1.0.0.0,1.0.0.255,A\n1.0.0.128,1.0.1.255,B\n",
+		"reversed": "1.0.0.255,1.0.0.0,A\n",
+		"family":   "1.0.0.0,2001:db8::,A\n",
+		"invalid":  "1.0.0,1.0.0.255,A\n",
+	}
+	for name, data := range cases {
+		if _, err := LoadCSV(strings.NewReader(data), CSVOptions{Columns: CSVColumns{Start: 1, End: 2, Country: 3}}); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	// 分号分隔且列顺序不同
+	data := "# comment\nLyon;FR;2.0.0.0;2.0.0.255\n"
+	db, err := LoadCSV(strings.NewReader(data), CSVOptions{
+		Columns: CSVColumns{Start: 3, End: 4, CountryCode: 2, City: 1},
+		Comma:   ';',
+		Comment: '#',
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := db.Lookup(context.Background(), "2.0.0.1"); err != nil || info.City != "Lyon" || info.CountryCode != "FR" {
+		t.Fatalf("column mapping failed: %v %+v", err, info)
+	}
+}
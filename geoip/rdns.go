@@ -0,0 +1,106 @@
+package geoip
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// Enricher 在 provider 返回结果之后补充信息，在写入缓存之前执行
+// ip 为查询的地址，provider 可能不返回 Info.IP；失败时保留 info 原样，不影响查询结果
+type Enricher interface {
+	Enrich(ctx context.Context, ip string, info *Info)
+}
+
+// DefaultReverseDNSTimeout 反向解析与正向确认的总超时
+const DefaultReverseDNSTimeout = time.Second
+
+// ReverseDNS 反向解析 PTR 并通过正向解析确认（FCrDNS），设置 Info.Hostname 与 Info.HostnameVerified
+//
+//	engine := geoip.New(geoip.English, geoip.WithEnrichers(geoip.NewReverseDNS(nil)))
+type ReverseDNS struct {
+	resolver *net.Resolver
+	timeout  time.Duration
+}
+
+// NewReverseDNS resolver 为 nil 时使用 net.DefaultResolver，超时为 DefaultReverseDNSTimeout
+func NewReverseDNS(resolver *net.Resolver) *ReverseDNS {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &ReverseDNS{resolver: resolver, timeout: DefaultReverseDNSTimeout}
+}
+
+// WithTimeout 设置反向解析与正向确认的总超时
+func (r *ReverseDNS) WithTimeout(d time.Duration) *ReverseDNS {
+	c := *r
+	c.timeout = d
+	return &c
+}
+
+// Resolve 返回 ip 的 PTR 主机名，以及该主机名是否正向解析回 ip
+// 有多个 PTR 记录时优先返回通过确认的主机名，主机名为小写且不含末尾的点
+func (r *ReverseDNS) Resolve(ctx context.Context, ip string) (hostname string, verified bool, err error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", false, ErrInvalidIP
+	}
+	addr = addr.Unmap()
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	names, err := r.resolver.LookupAddr(ctx, addr.String())
+	if err != nil {
+		return "", false, err
+	}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == "" {
+			continue
+		}
+		if hostname == "" {
+			hostname = name
+		}
+		addrs, err := r.resolver.LookupNetIP(ctx, "ip", name+".")
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if a.Unmap() == addr {
+				return name, true, nil
+			}
+		}
+	}
+	return hostname, false, nil
+}
+
+// Enrich implements Enricher.
+func (r *ReverseDNS) Enrich(ctx context.Context, ip string, info *Info) {
+	hostname, verified, err := r.Resolve(ctx, ip)
+	if err != nil || hostname == "" {
+		return
+	}
+	info.Hostname, info.HostnameVerified = hostname, verified
+}
+
+// HostnameIn 主机名通过正向确认，且为 domains 之一或其子域名，用于识别爬虫
+//
+//	info.HostnameIn("googlebot.com", "google.com") // Googlebot
+//	info.HostnameIn("search.msn.com")              // Bingbot
+func (i *Info) HostnameIn(domains ...string) bool {
+	if i == nil || !i.HostnameVerified || i.Hostname == "" {
+		return false
+	}
+	for _, d := range domains {
+		d = strings.ToLower(strings.Trim(d, "."))
+		if i.Hostname == d || strings.HasSuffix(i.Hostname, "."+d) {
+			return true
+		}
+	}
+	return false
+}
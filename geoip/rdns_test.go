@@ -0,0 +1,63 @@
+package geoip
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReverseDNS(t *testing.T) {
+	resolver := serveDNS(t, map[string][]string{
+		"PTR 1.249.249.66.in-addr.arpa.":      {"crawl-66-249-249-1.googlebot.com."},
+		"A crawl-66-249-249-1.googlebot.com.": {"66.249.249.1"},
+		// 伪造的 PTR，正向解析不到原地址
+		"PTR 7.113.0.203.in-addr.arpa.":  {"crawl-1-2-3-4.googlebot.com."},
+		"A crawl-1-2-3-4.googlebot.com.": {"1.2.3.4"},
+		// 多个 PTR 时使用通过确认的主机名
+		"PTR 8.113.0.203.in-addr.arpa.":                                                 {"stale.example.com.", "MSNBOT-203-0-113-8.search.msn.com."},
+		"A msnbot-203-0-113-8.search.msn.com.":                                          {"203.0.113.8"},
+		"PTR 1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.": {"host.example.net."},
+		"AAAA host.example.net.":                                                        {"2001:db8::1"},
+	})
+	r := NewReverseDNS(resolver)
+	ctx := context.Background()
+
+	for ip, want := range map[string]struct {
+		hostname string
+		verified bool
+	}{
+		"66.249.249.1": {"crawl-66-249-249-1.googlebot.com", true},
+		"203.0.113.7":  {"crawl-1-2-3-4.googlebot.com", false},
+		"203.0.113.8":  {"msnbot-203-0-113-8.search.msn.com", true},
+		"2001:db8::1":  {"host.example.net", true},
+	} {
+		hostname, verified, err := r.Resolve(ctx, ip)
+		if err != nil || hostname != want.hostname || verified != want.verified {
+			t.Fatalf("resolve %s not match, got: %s %v %v", ip, hostname, verified, err)
+		}
+	}
+	if _, _, err := r.Resolve(ctx, "203.0.113.9"); err == nil {
+		t.Fatal("expected error without ptr")
+	}
+
+	db, err := LoadCSV(strings.NewReader("66.249.0.0,66.249.255.255,美国,加利福尼亚州,山景城,谷歌\n203.0.113.0,203.0.113.255,中国,湖北省,荆门市,联通\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(Chinese, WithHandlers(db), WithEnrichers(r))
+	info, err := e.Lookup(ctx, "66.249.249.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.HostnameIn("googlebot.com", "google.com") || info.HostnameIn("search.msn.com") {
+		t.Fatalf("googlebot not match, got: %s %v", info.Hostname, info.HostnameVerified)
+	}
+	info, _ = e.Lookup(ctx, "203.0.113.7")
+	if info.Hostname != "crawl-1-2-3-4.googlebot.com" || info.HostnameIn("googlebot.com") {
+		t.Fatalf("spoofed ptr not match, got: %s %v", info.Hostname, info.HostnameVerified)
+	}
+	// 没有 PTR 不影响查询结果
+	if info, err = e.Lookup(ctx, "203.0.113.9"); err != nil || info.City != "荆门市" || info.Hostname != "" {
+		t.Fatalf("no ptr not match, got: %+v %v", info, err)
+	}
+}
@@ -0,0 +1,116 @@
+package geoip
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Credential 描述 provider 所需的凭据
+type Credential struct {
+	Name     string // 凭据名称，如 key、token
+	Optional bool   // 为 true 时缺省也可以正常使用
+}
+
+// Credentials provider 的凭据值，键为 Credential.Name
+type Credentials map[string]string
+
+// Provider 描述一个可以通过名称构建的 IPer
+type Provider struct {
+	Name        string       // 唯一名称，如 ipapi、gaode
+	Description string       // 简要说明
+	Languages   []Language   // 返回结果所使用的语言
+	Credentials []Credential // 所需凭据
+	New         func(Credentials) (IPer, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	providers map[string]Provider
+}{
+	providers: make(map[string]Provider),
+}
+
+// Register 注册 provider，名称重复或 New 为空时 panic
+// 一般在 provider 所在文件的 init 中调用
+func Register(p Provider) {
+	if p.Name == "" || p.New == nil {
+		panic("geoip: Register provider with empty name or nil New")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.providers[p.Name]; ok {
+		panic("geoip: Register called twice for provider " + p.Name)
+	}
+	registry.providers[p.Name] = p
+}
+
+// Providers 返回按名称排序的已注册 provider
+func Providers() []Provider {
+	registry.RLock()
+	defer registry.RUnlock()
+	out := make([]Provider, 0, len(registry.providers))
+	for _, p := range registry.providers {
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(a, b Provider) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// GetProvider 根据名称获取已注册的 provider
+func GetProvider(name string) (Provider, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	p, ok := registry.providers[name]
+	return p, ok
+}
+
+// NewProvider 根据名称与凭据构建 IPer，缺少必需凭据时返回 ErrMissingCredential
+func NewProvider(name string, creds Credentials) (IPer, error) {
+	p, ok := GetProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	for _, c := range p.Credentials {
+		if !c.Optional && creds[c.Name] == "" {
+			return nil, fmt.Errorf("%w: %s requires %s (%s)", ErrMissingCredential, name, c.Name, EnvName(name, c.Name))
+		}
+	}
+	return p.New(creds)
+}
+
+// EnvName 返回 provider 凭据对应的环境变量名，如 EnvName("gaode", "key") 为 NETPULSE_GAODE_KEY
+func EnvName(provider, credential string) string {
+	return envName(envPrefix + provider + "_" + credential)
+}
+
+func envName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// credentialsFromEnv 使用环境变量补全缺省的凭据
+func credentialsFromEnv(p Provider, creds Credentials) Credentials {
+	out := make(Credentials, len(p.Credentials))
+	for k, v := range creds {
+		out[k] = v
+	}
+	for _, c := range p.Credentials {
+		if out[c.Name] != "" {
+			continue
+		}
+		if v := os.Getenv(EnvName(p.Name, c.Name)); v != "" {
+			out[c.Name] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,244 @@
+package geoip
+
+import (
+	"hash/maphash"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultShardCount 默认分片数量
+const defaultShardCount = 32
+
+var (
+	_ MapStore[string, int] = (*Map[string, int])(nil)
+	_ MapStore[string, int] = (*ShardedMap[string, int])(nil)
+)
+
+// MapStore 键值存储接口，Map 与 ShardedMap 均实现了该接口
+// TTLMap 可通过 NewTTLMapWithStore 选择底层存储
+type MapStore[K comparable, V any] interface {
+	Store(key K, value V)
+	Load(key K) (V, bool)
+	LoadOrStore(key K, value V) (V, bool)
+	Delete(key K)
+	Range(f func(key K, value V) bool)
+	Len() int
+	Clear()
+}
+
+// ShardedMap 分片加锁的泛型并发 map
+// 与基于 sync.Map 的 Map 相比，Len 为 O(1)，Clear 按分片整体替换，
+// 适合写入频繁或需要经常统计长度的场景
+type ShardedMap[K comparable, V any] struct {
+	seed   maphash.Seed
+	shards []mapShard[K, V]
+	mask   uint64
+	count  atomic.Int64
+}
+
+type mapShard[K comparable, V any] struct {
+	mu   sync.RWMutex
+	data map[K]V
+}
+
+// NewShardedMap 创建分片 map，shards 会向上取整为 2 的幂，小于等于 0 时使用默认值 32
+func NewShardedMap[K comparable, V any](shards int) *ShardedMap[K, V] {
+	if shards <= 0 {
+		shards = defaultShardCount
+	}
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	m := ShardedMap[K, V]{
+		seed:   maphash.MakeSeed(),
+		shards: make([]mapShard[K, V], n),
+		mask:   uint64(n - 1),
+	}
+	for i := range m.shards {
+		m.shards[i].data = make(map[K]V)
+	}
+	return &m
+}
+
+func (m *ShardedMap[K, V]) shard(key K) *mapShard[K, V] {
+	return &m.shards[maphash.Comparable(m.seed, key)&m.mask]
+}
+
+// Store 存储键值对
+func (m *ShardedMap[K, V]) Store(key K, value V) {
+	s := m.shard(key)
+	s.mu.Lock()
+	if _, ok := s.data[key]; !ok {
+		m.count.Add(1)
+	}
+	s.data[key] = value
+	s.mu.Unlock()
+}
+
+// Load 根据键获取值
+func (m *ShardedMap[K, V]) Load(key K) (value V, ok bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	value, ok = s.data[key]
+	s.mu.RUnlock()
+	return
+}
+
+// LoadOrStore 获取或存储键值对，loaded 为 true 表示返回的是已存在的值
+func (m *ShardedMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.data[key]; ok {
+		return v, true
+	}
+	s.data[key] = value
+	m.count.Add(1)
+	return value, false
+}
+
+// LoadOrCompute 获取键对应的值，不存在时调用 fn 计算并存储
+// 同一个键的 fn 在加锁期间执行，不会被并发重复调用，fn 内不可再操作当前 map
+func (m *ShardedMap[K, V]) LoadOrCompute(key K, fn func() V) (actual V, loaded bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	v, ok := s.data[key]
+	s.mu.RUnlock()
+	if ok {
+		return v, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.data[key]; ok {
+		return v, true
+	}
+	v = fn()
+	s.data[key] = v
+	m.count.Add(1)
+	return v, false
+}
+
+// Compute 原子地更新键对应的值
+// fn 接收旧值及其是否存在，返回新值；keep 为 false 时删除该键
+// 返回值为最终存储的值及其是否存在，fn 内不可再操作当前 map
+func (m *ShardedMap[K, V]) Compute(key K, fn func(old V, loaded bool) (value V, keep bool)) (actual V, ok bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, loaded := s.data[key]
+	value, keep := fn(old, loaded)
+	switch {
+	case keep:
+		s.data[key] = value
+		if !loaded {
+			m.count.Add(1)
+		}
+		return value, true
+	case loaded:
+		delete(s.data, key)
+		m.count.Add(-1)
+	}
+	var zero V
+	return zero, false
+}
+
+// LoadAndDelete 获取并删除键值对
+func (m *ShardedMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	value, loaded = s.data[key]
+	if loaded {
+		delete(s.data, key)
+		m.count.Add(-1)
+	}
+	s.mu.Unlock()
+	return
+}
+
+// Delete 删除键值对
+func (m *ShardedMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+// Swap 交换键对应的值
+func (m *ShardedMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	s := m.shard(key)
+	s.mu.Lock()
+	previous, loaded = s.data[key]
+	if !loaded {
+		m.count.Add(1)
+	}
+	s.data[key] = value
+	s.mu.Unlock()
+	return
+}
+
+// All 返回遍历所有键值对的迭代器
+// 每个分片在遍历前会复制一份快照，遍历期间可以安全地读写当前 map
+func (m *ShardedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for i := range m.shards {
+			s := &m.shards[i]
+			s.mu.RLock()
+			keys := make([]K, 0, len(s.data))
+			values := make([]V, 0, len(s.data))
+			for k, v := range s.data {
+				keys = append(keys, k)
+				values = append(values, v)
+			}
+			s.mu.RUnlock()
+
+			for j, k := range keys {
+				if !yield(k, values[j]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Range 遍历所有键值对，语义与 All 相同
+func (m *ShardedMap[K, V]) Range(f func(key K, value V) bool) {
+	for k, v := range m.All() {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// Len 返回 map 中键值对的数量，时间复杂度 O(1)
+func (m *ShardedMap[K, V]) Len() int {
+	return int(m.count.Load())
+}
+
+// Keys 返回所有键的切片
+func (m *ShardedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for k := range m.All() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values 返回所有值的切片
+func (m *ShardedMap[K, V]) Values() []V {
+	values := make([]V, 0, m.Len())
+	for _, v := range m.All() {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Clear 清空所有键值对
+func (m *ShardedMap[K, V]) Clear() {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.Lock()
+		m.count.Add(-int64(len(s.data)))
+		s.data = make(map[K]V)
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,170 @@
+package geoip
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedMap(t *testing.T) {
+	m := NewShardedMap[string, int](3)
+	if len(m.shards) != 4 {
+		t.Fatalf("shards not match, got: %d", len(m.shards))
+	}
+
+	m.Store("a", 1)
+	m.Store("b", 2)
+	m.Store("a", 3)
+	if m.Len() != 2 {
+		t.Fatalf("len not match, got: %d", m.Len())
+	}
+	if v, ok := m.Load("a"); !ok || v != 3 {
+		t.Fatalf("load not match, got: %d %v", v, ok)
+	}
+
+	if v, loaded := m.LoadOrStore("c", 4); loaded || v != 4 {
+		t.Fatalf("load or store not match, got: %d %v", v, loaded)
+	}
+	if v, loaded := m.LoadOrCompute("c", func() int { return 5 }); !loaded || v != 4 {
+		t.Fatalf("load or compute not match, got: %d %v", v, loaded)
+	}
+
+	v, ok := m.Compute("a", func(old int, loaded bool) (int, bool) { return old + 1, true })
+	if !ok || v != 4 {
+		t.Fatalf("compute not match, got: %d %v", v, ok)
+	}
+	if _, ok := m.Compute("b", func(int, bool) (int, bool) { return 0, false }); ok {
+		t.Fatal("compute should delete key")
+	}
+	if m.Len() != 2 {
+		t.Fatalf("len not match, got: %d", m.Len())
+	}
+
+	keys := m.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"a", "c"}) {
+		t.Fatalf("keys not match, got: %v", keys)
+	}
+
+	for k := range m.All() {
+		m.Delete(k)
+	}
+	if m.Len() != 0 {
+		t.Fatalf("len not match, got: %d", m.Len())
+	}
+
+	m.Store("x", 1)
+	m.Clear()
+	if _, ok := m.Load("x"); ok || m.Len() != 0 {
+		t.Fatal("clear failed")
+	}
+}
+
+func TestShardedMapConcurrent(t *testing.T) {
+	m := NewShardedMap[int, int](0)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				m.Compute(i, func(old int, _ bool) (int, bool) { return old + 1, true })
+				if i%2 == g%2 {
+					m.Load(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if m.Len() != 1000 {
+		t.Fatalf("len not match, got: %d", m.Len())
+	}
+	for k, v := range m.All() {
+		if v != 8 {
+			t.Fatalf("value of %d not match, got: %d", k, v)
+		}
+	}
+}
+
+func TestTTLMapWithShardedStore(t *testing.T) {
+	c := NewTTLMapWithStore(NewShardedMap[string, int](0), NewShardedMap[string, time.Time](0))
+	defer c.Dispose()
+
+	c.Store("a", 1, time.Hour)
+	c.Store("b", 2, -time.Second)
+	if v, ok := c.Load("a"); !ok || v != 1 {
+		t.Fatalf("load not match, got: %d %v", v, ok)
+	}
+	if _, ok := c.Load("b"); ok {
+		t.Fatal("expired key should not be loaded")
+	}
+	if c.Len() != 1 {
+		t.Fatalf("len not match, got: %d", c.Len())
+	}
+
+	c.Store("c", 3, -time.Second)
+	var keys []string
+	c.RangeUnexpired(func(k string, _ int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if len(keys) != 1 || keys[0] != "a" {
+		t.Fatalf("unexpired keys not match, got: %v", keys)
+	}
+}
+
+func benchmarkStore(b *testing.B, m MapStore[string, int]) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			m.Store(keys[i%len(keys)], i)
+			i++
+		}
+	})
+}
+
+func benchmarkMixed(b *testing.B, m MapStore[string, int]) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		m.Store(keys[i], i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%10 == 0 {
+				m.Store(key, i)
+			} else {
+				m.Load(key)
+			}
+			i++
+		}
+	})
+}
+
+func benchmarkLen(b *testing.B, m MapStore[string, int]) {
+	for i := range 10000 {
+		m.Store(strconv.Itoa(i), i)
+	}
+	b.ResetTimer()
+	for b.Loop() {
+		_ = m.Len()
+	}
+}
+
+func BenchmarkMapStore(b *testing.B)        { benchmarkStore(b, NewMap[string, int]()) }
+func BenchmarkShardedMapStore(b *testing.B) { benchmarkStore(b, NewShardedMap[string, int](0)) }
+func BenchmarkMapMixed(b *testing.B)        { benchmarkMixed(b, NewMap[string, int]()) }
+func BenchmarkShardedMapMixed(b *testing.B) { benchmarkMixed(b, NewShardedMap[string, int](0)) }
+func BenchmarkMapLen(b *testing.B)          { benchmarkLen(b, NewMap[string, int]()) }
+func BenchmarkShardedMapLen(b *testing.B)   { benchmarkLen(b, NewShardedMap[string, int](0)) }
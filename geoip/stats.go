@@ -0,0 +1,108 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats Engine 的查询统计
+type Stats struct {
+	Lookups   uint64          // 有效的公网 IP 查询次数
+	CacheHits uint64          // 命中缓存的次数
+	Failures  uint64          // 所有 provider 均失败的次数
+	Providers []ProviderStats // 按 provider 的顺序
+}
+
+// ProviderStats 单个 provider 的统计
+type ProviderStats struct {
+	Name     string // provider 的类型名，如 WhoisPconline
+	Requests uint64
+	Failures uint64
+}
+
+type providerCounter struct {
+	name     string
+	requests atomic.Uint64
+	failures atomic.Uint64
+}
+
+func (c *providerCounter) record(err error) {
+	c.requests.Add(1)
+	if err != nil {
+		c.failures.Add(1)
+	}
+}
+
+type engineStats struct {
+	lookups   atomic.Uint64
+	cacheHits atomic.Uint64
+	failures  atomic.Uint64
+	providers []providerCounter
+}
+
+func newEngineStats(handlers []IPer) *engineStats {
+	s := engineStats{providers: make([]providerCounter, len(handlers))}
+	for i, h := range handlers {
+		name := fmt.Sprintf("%T", h)
+		s.providers[i].name = name[strings.LastIndexByte(name, '.')+1:]
+	}
+	return &s
+}
+
+// Stats 返回 Engine 创建以来的查询统计
+func (e *Engine) Stats() Stats {
+	s := Stats{
+		Lookups:   e.stats.lookups.Load(),
+		CacheHits: e.stats.cacheHits.Load(),
+		Failures:  e.stats.failures.Load(),
+		Providers: make([]ProviderStats, len(e.stats.providers)),
+	}
+	for i := range e.stats.providers {
+		p := &e.stats.providers[i]
+		s.Providers[i] = ProviderStats{Name: p.name, Requests: p.requests.Load(), Failures: p.failures.Load()}
+	}
+	return s
+}
+
+// BatchResult LookupBatch 中单个 IP 的结果
+type BatchResult struct {
+	IP   string
+	Info *Info
+	Err  error
+}
+
+// LookupBatch 并发查询多个 IP，结果与 ips 的顺序一致，concurrency 小于等于 0 时为 8
+func (e *Engine) LookupBatch(ctx context.Context, ips []string, concurrency int) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	out := make([]BatchResult, len(ips))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		out[i].IP = ip
+		if err := ctx.Err(); err != nil {
+			out[i].Err = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			out[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			out[i].Info, out[i].Err = e.Lookup(ctx, ip)
+		}()
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,32 @@
+package geoip
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStatsAndBatch(t *testing.T) {
+	e := New(English, WithHandlers(
+		policyIPer{},
+		policyIPer{"8.8.8.8": {IP: "8.8.8.8", Country: "United States"}},
+	))
+	got := e.LookupBatch(context.Background(), []string{"8.8.8.8", "9.9.9.9", "8.8.8.8", "10.0.0.1"}, 2)
+	if len(got) != 4 || got[0].Info == nil || got[1].Err == nil || got[2].IP != "8.8.8.8" || !IsErrPrivateIP(got[3].Err) {
+		t.Fatalf("batch not match, got: %+v", got)
+	}
+
+	s := e.Stats()
+	// 两次 8.8.8.8 可能同时查询，不一定命中缓存
+	if s.Lookups != 3 || s.Failures != 1 || s.CacheHits+s.Providers[1].Requests != 3 {
+		t.Fatalf("stats not match, got: %+v", s)
+	}
+	if len(s.Providers) != 2 || s.Providers[0].Name != "policyIPer" || s.Providers[0].Failures != s.Providers[0].Requests {
+		t.Fatalf("provider stats not match, got: %+v", s.Providers)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := e.LookupBatch(ctx, []string{"8.8.8.8"}, 1); got[0].Err == nil {
+		t.Fatalf("canceled batch not match, got: %+v", got)
+	}
+}
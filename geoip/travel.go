@@ -0,0 +1,122 @@
+package geoip
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+)
+
+// earthRadius 地球平均半径，单位 km
+const earthRadius = 6371.0088
+
+// 异地登录检测的默认参数
+const (
+	DefaultMaxSpeed       = 1000.0 // 民航客机的巡航速度，km/h
+	DefaultAccuracyRadius = 50     // 没有精度半径时按城市级定位估计，km
+)
+
+// HaversineDistance 返回两个 WGS-84 坐标之间的大圆距离，单位 km
+func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// HasLocation 是否有坐标，经纬度均为 0 视为没有
+func (i *Info) HasLocation() bool {
+	return i != nil && (i.Latitude != 0 || i.Longitude != 0)
+}
+
+// Distance 返回两次查询结果之间的距离，单位 km，任一方没有坐标时返回 false
+func Distance(a, b *Info) (float64, bool) {
+	if !a.HasLocation() || !b.HasLocation() {
+		return 0, false
+	}
+	return HaversineDistance(a.Latitude, a.Longitude, b.Latitude, b.Longitude), true
+}
+
+// TravelEvent 用户的一次登录等行为
+type TravelEvent struct {
+	Time time.Time
+	IP   string
+}
+
+// TravelOptions 异地登录检测参数
+type TravelOptions struct {
+	// MaxSpeed 允许的最大速度，km/h，为 0 时使用 DefaultMaxSpeed
+	MaxSpeed float64
+	// AccuracyRadius 结果没有精度半径时使用的值，km，为 0 时使用 DefaultAccuracyRadius
+	AccuracyRadius int
+}
+
+// Transition 相邻两次可定位行为之间的移动
+type Transition struct {
+	From, To         TravelEvent
+	FromInfo, ToInfo *Info
+	Distance         float64       // 两点的距离，km
+	MinDistance      float64       // 扣除两侧精度半径后的最短距离，km
+	Duration         time.Duration // 两次行为的时间间隔
+	Speed            float64       // 按 MinDistance 计算的最低速度，km/h，间隔为 0 且 MinDistance 大于 0 时为 +Inf
+	Impossible       bool          // Speed 超过 MaxSpeed
+}
+
+// EvaluateTravel 按时间排序解析 events 的位置，计算相邻两次可定位行为之间的移动速度
+// 无法查询或没有坐标的行为会被跳过，与下一次可定位的行为比较
+// 只在 ctx 结束时返回错误
+func (e *Engine) EvaluateTravel(ctx context.Context, events []TravelEvent, opt TravelOptions) ([]Transition, error) {
+	if opt.MaxSpeed <= 0 {
+		opt.MaxSpeed = DefaultMaxSpeed
+	}
+	if opt.AccuracyRadius <= 0 {
+		opt.AccuracyRadius = DefaultAccuracyRadius
+	}
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b TravelEvent) int { return a.Time.Compare(b.Time) })
+
+	var out []Transition
+	var prev *TravelEvent
+	var prevInfo *Info
+	for i := range events {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+		info, err := e.Lookup(ctx, events[i].IP)
+		if err != nil || !info.HasLocation() {
+			continue
+		}
+		if prev != nil {
+			out = append(out, newTransition(*prev, events[i], prevInfo, info, opt))
+		}
+		prev, prevInfo = &events[i], info
+	}
+	return out, nil
+}
+
+func newTransition(from, to TravelEvent, fromInfo, toInfo *Info, opt TravelOptions) Transition {
+	radius := func(i *Info) float64 {
+		if i.AccuracyRadius > 0 {
+			return float64(i.AccuracyRadius)
+		}
+		return float64(opt.AccuracyRadius)
+	}
+	t := Transition{
+		From: from, To: to, FromInfo: fromInfo, ToInfo: toInfo,
+		Duration: to.Time.Sub(from.Time),
+	}
+	t.Distance, _ = Distance(fromInfo, toInfo)
+	t.MinDistance = math.Max(0, t.Distance-radius(fromInfo)-radius(toInfo))
+	switch {
+	case t.MinDistance == 0:
+		t.Speed = 0
+	case t.Duration <= 0:
+		t.Speed = math.Inf(1)
+	default:
+		t.Speed = t.MinDistance / t.Duration.Hours()
+	}
+	t.Impossible = t.Speed > opt.MaxSpeed
+	return t
+}
@@ -0,0 +1,77 @@
+package geoip
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDistance(t *testing.T) {
+	// 北京到上海约 1068 km
+	beijing := &Info{Latitude: 39.9042, Longitude: 116.4074}
+	shanghai := &Info{Latitude: 31.2304, Longitude: 121.4737}
+	d, ok := Distance(beijing, shanghai)
+	if !ok || math.Abs(d-1068) > 5 {
+		t.Fatalf("distance not match, got: %f", d)
+	}
+	if _, ok := Distance(beijing, &Info{}); ok {
+		t.Fatal("expected no distance without coordinates")
+	}
+	if d := HaversineDistance(0, 0, 0, 180); math.Abs(d-math.Pi*earthRadius) > 1e-6 {
+		t.Fatalf("antipodal distance not match, got: %f", d)
+	}
+}
+
+func TestEvaluateTravel(t *testing.T) {
+	const data = "" +
+		"1.1.1.0,1.1.1.255,CN,Beijing,Beijing,39.9042,116.4074\n" +
+		"1.1.2.0,1.1.2.255,CN,Beijing,Chaoyang,39.9219,116.4436\n" +
+		"2.2.2.0,2.2.2.255,CN,Shanghai,Shanghai,31.2304,121.4737\n" +
+		"3.3.3.0,3.3.3.255,US,New York,New York,40.7128,-74.0060\n"
+	db, err := LoadCSV(strings.NewReader(data), CSVOptions{
+		Columns: CSVColumns{Start: 1, End: 2, CountryCode: 3, Region: 4, City: 5, Latitude: 6, Longitude: 7},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(English, WithHandlers(db))
+
+	base := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	events := []TravelEvent{
+		{base.Add(5 * time.Hour), "2.2.2.2"},    // 上海，5 小时后，正常
+		{base, "1.1.1.1"},                       // 北京
+		{base.Add(time.Minute), "1.1.2.1"},      // 北京朝阳，在精度半径内
+		{base.Add(2 * time.Minute), "10.0.0.1"}, // 内网地址，跳过
+		{base.Add(6 * time.Hour), "3.3.3.3"},    // 纽约，1 小时后，不可能
+	}
+	got, err := e.EvaluateTravel(context.Background(), events, TravelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("transitions not match, got: %d", len(got))
+	}
+	if got[0].Impossible || got[0].Speed != 0 || got[0].Distance == 0 {
+		t.Fatalf("nearby transition not match, got: %+v", got[0])
+	}
+	if got[1].Impossible || got[1].From.IP != "1.1.2.1" || got[1].To.IP != "2.2.2.2" {
+		t.Fatalf("beijing to shanghai not match, got: %+v", got[1])
+	}
+	if !got[2].Impossible || got[2].Speed < 10000 {
+		t.Fatalf("shanghai to new york not match, got: %+v", got[2])
+	}
+
+	// 更高的速度阈值
+	got, _ = e.EvaluateTravel(context.Background(), events, TravelOptions{MaxSpeed: 20000})
+	if got[2].Impossible {
+		t.Fatalf("max speed not applied, got: %+v", got[2])
+	}
+
+	// 同一时刻不同城市
+	got, _ = e.EvaluateTravel(context.Background(), []TravelEvent{{base, "1.1.1.1"}, {base, "2.2.2.2"}}, TravelOptions{})
+	if len(got) != 1 || !got[0].Impossible || !math.IsInf(got[0].Speed, 1) {
+		t.Fatalf("same time transition not match, got: %+v", got)
+	}
+}
@@ -7,8 +7,8 @@ import (
 
 // TTLMap 带有过期时间的 map
 type TTLMap[K comparable, V any] struct {
-	data Map[K, V]
-	exp  Map[K, time.Time]
+	data MapStore[K, V]
+	exp  MapStore[K, time.Time]
 
 	cancel context.CancelFunc
 }
@@ -16,7 +16,13 @@ type TTLMap[K comparable, V any] struct {
 // NewTTLMap 提供默认的过期删除
 // 也可以使用 SwichFixedTimeCleanup 开启定时清空
 func NewTTLMap[K comparable, V any]() *TTLMap[K, V] {
-	c := TTLMap[K, V]{}
+	return NewTTLMapWithStore(NewMap[K, V](), NewMap[K, time.Time]())
+}
+
+// NewTTLMapWithStore 使用指定的底层存储创建 TTLMap
+// 例如 NewTTLMapWithStore(NewShardedMap[K, V](0), NewShardedMap[K, time.Time](0))
+func NewTTLMapWithStore[K comparable, V any](data MapStore[K, V], exp MapStore[K, time.Time]) *TTLMap[K, V] {
+	c := TTLMap[K, V]{data: data, exp: exp}
 	ctx, cancel := context.WithCancel(context.Background())
 	c.cancel = cancel
 	go c.tickerCleanup(ctx, 0)
@@ -128,6 +134,17 @@ func (c *TTLMap[K, V]) Range(fn func(key K, value V) bool) {
 	c.data.Range(fn)
 }
 
+// RangeUnexpired 遍历未过期的 k/v，过期但尚未被清理的数据会被跳过
+func (c *TTLMap[K, V]) RangeUnexpired(fn func(key K, value V) bool) {
+	now := time.Now()
+	c.data.Range(func(key K, value V) bool {
+		if expAt, ok := c.exp.Load(key); !ok || now.After(expAt) {
+			return true
+		}
+		return fn(key, value)
+	})
+}
+
 // Clear 清空数据
 func (c *TTLMap[K, V]) Clear() {
 	c.data.Clear()
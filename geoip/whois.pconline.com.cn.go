@@ -48,6 +48,15 @@ func (i *whoisPconlineInfo) toInfo() *Info {
 	}
 }
 
+func init() {
+	Register(Provider{
+		Name:        "pconline",
+		Description: "太平洋网络 whois.pconline.com.cn，仅支持国内 IP",
+		Languages:   []Language{Chinese},
+		New:         func(Credentials) (IPer, error) { return NewWhoisPconline(), nil },
+	})
+}
+
 type whoisPconline struct {
 	wrapBody WrapBodyHandler
 }
@@ -2,4 +2,8 @@ module github.com/ixugo/netpulse
 
 go 1.24.0
 
-require golang.org/x/text v0.28.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	golang.org/x/text v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
+)
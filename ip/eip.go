@@ -3,7 +3,10 @@ package ip
 import (
 	"context"
 	"io"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 )
 
@@ -15,25 +18,59 @@ var services = []string{
 	"https://icanhazip.com",
 	"https://api.ipify.org",
 	"https://ifconfig.co/ip",
+}
 
-	// ipv6?
-	// https://api64.ipify.org
+// services6 仅返回 IPv6 的服务，请求通过 DefaultClient6 强制走 IPv6
+var services6 = []string{
+	"https://api6.ipify.org",
+	"https://ipv6.icanhazip.com",
+	"https://v6.ident.me",
+	"https://api64.ipify.org",
+	"https://ifconfig.co/ip",
 }
 
-var defaultServices = func() *StringScorer {
-	ss := NewStringScorer(8)
-	for _, s := range services {
+var defaultServices = newScorer(services)
+
+var defaultServices6 = newScorer(services6)
+
+func newScorer(links []string) *StringScorer {
+	ss := NewStringScorer(len(links))
+	for _, s := range links {
 		ss.Set(s)
 	}
 	return ss
-}()
+}
 
 var DefaultClient = http.Client{
 	Timeout: 5 * time.Second,
 }
 
+// DefaultClient6 只通过 IPv6 建立连接
+var DefaultClient6 = http.Client{
+	Timeout: 5 * time.Second,
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp6", addr)
+		},
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+// ExternalIP 返回公网 IPv4 地址
 func ExternalIP() (string, error) {
-	return multipleRequests()
+	return multipleRequests(defaultServices, &DefaultClient, func(addr netip.Addr) bool {
+		return addr.Unmap().Is4()
+	})
+}
+
+// ExternalIPv6 返回公网 IPv6 地址，本机没有 IPv6 出口时返回错误
+func ExternalIPv6() (string, error) {
+	return multipleRequests(defaultServices6, &DefaultClient6, func(addr netip.Addr) bool {
+		return addr.Is6() && !addr.Is4In6()
+	})
 }
 
 type value struct {
@@ -41,7 +78,8 @@ type value struct {
 	err error
 }
 
-func multipleRequests() (string, error) {
+// multipleRequests 并发请求 ss 中的服务，返回第一个通过 valid 校验的地址
+func multipleRequests(ss *StringScorer, client *http.Client, valid func(netip.Addr) bool) (string, error) {
 	// 创建可取消的context用于取消其他请求
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(5*time.Second))
 	defer cancel()
@@ -50,7 +88,7 @@ func multipleRequests() (string, error) {
 
 	// 启动所有请求，每个请求都使用带超时的context
 	var i int
-	for link := range defaultServices.All() {
+	for link := range ss.All() {
 		i++
 		select {
 		case <-ctx.Done():
@@ -63,7 +101,7 @@ func multipleRequests() (string, error) {
 					return
 				}
 
-				resp, err := DefaultClient.Do(req)
+				resp, err := client.Do(req)
 				if err != nil {
 					return
 				}
@@ -74,16 +112,22 @@ func multipleRequests() (string, error) {
 				}
 
 				body, err := io.ReadAll(io.LimitReader(resp.Body, 128))
+				if err != nil {
+					return
+				}
+				// 部分服务返回的内容带有换行，且 api64 等服务在没有 IPv6 时会返回 IPv4
+				ip := strings.TrimSpace(string(body))
+				if addr, err := netip.ParseAddr(ip); err != nil || !valid(addr) {
+					return
+				}
 
 				// 尝试发送结果，如果channel已满或其他请求先发送了，select会处理
-				v := value{ip: string(body), err: err}
+				v := value{ip: ip}
 				select {
 				case ch <- &v:
 					// 发送成功，取消其他所有请求
 					cancel()
-					if err == nil && v.ip != "" {
-						defaultServices.AddScore(link)
-					}
+					ss.AddScore(link)
 				case <-ctx.Done():
 					// 已经被取消或超时
 					return
@@ -20,3 +20,11 @@ func TestInternalIP(t *testing.T) {
 	ip = localIP()
 	t.Log(ip)
 }
+
+func TestExternalIPv6(t *testing.T) {
+	ip, err := ExternalIPv6()
+	if err != nil {
+		t.Skip("no ipv6:", err)
+	}
+	t.Log(ip)
+}
@@ -43,9 +43,12 @@ func NewStringScorer(length int) *StringScorer {
 		scores: make(map[string]int, length),
 		data:   make([]string, 0, length),
 	}
-	expvar.Publish("netpulse", expvar.Func(func() interface{} {
-		return s.AllWithScores()
-	}))
+	// 同一进程内可能创建多个 StringScorer（如 IPv4/IPv6），只发布第一个，避免重复发布 panic
+	if expvar.Get("netpulse") == nil {
+		expvar.Publish("netpulse", expvar.Func(func() interface{} {
+			return s.AllWithScores()
+		}))
+	}
 
 	return &s
 }
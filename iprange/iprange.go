@@ -0,0 +1,167 @@
+// Package iprange 根据云厂商与爬虫公开的网段文件，判断 IP 是否属于 AWS、GCP、Azure、Cloudflare、阿里云、腾讯云或常见爬虫
+//
+// 内置 AWS、GCP、Azure 与 Cloudflare 的完整网段快照，可以直接使用
+//
+//	idx := iprange.Default()
+//	r, _ := idx.Match(netip.MustParseAddr("3.80.1.1")) // aws
+//
+// 快照只区分服务商，定期从 Source.URL 下载完整文件后通过 LoadDir 加载，可以得到 service 与 region，并识别爬虫
+//
+//	idx, err := iprange.LoadDir("/var/lib/netpulse/ranges") // aws.json、googlebot.json ...，缺少的文件使用内置快照
+//	ranges := idx.Lookup(netip.MustParseAddr("3.80.1.1")) // aws EC2 us-east-1
+//
+// Index 实现了 geoip.Enricher，查询时为 Info.Tags 添加 cloud、provider:aws、service:EC2、region:us-east-1 等标签
+//
+//	engine := geoip.New(geoip.English, geoip.WithEnrichers(idx))
+//	info, _ := engine.Lookup(ctx, ip)
+//	info.HasTag("provider:aws")
+package iprange
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+// Kind 网段的类别
+type Kind string
+
+const (
+	Cloud Kind = "cloud" // 云厂商
+	CDN   Kind = "cdn"   // CDN 与边缘网络
+	Bot   Kind = "bot"   // 搜索引擎爬虫
+)
+
+// Range 一条网段记录
+type Range struct {
+	Prefix   netip.Prefix
+	Provider string // aws、gcp、azure、cloudflare、alibaba、tencent、googlebot、bingbot
+	Kind     Kind
+	Service  string // 文件中的服务名称，如 EC2、Google Cloud、AzureFrontDoor，没有时为空
+	Region   string // 文件中的区域，如 us-east-1、asia-east1，没有时为空
+}
+
+// Tags 返回网段对应的标签，如 cloud、provider:aws、service:EC2、region:us-east-1
+func (r Range) Tags() []string {
+	tags := []string{string(r.Kind), "provider:" + r.Provider}
+	if r.Service != "" {
+		tags = append(tags, "service:"+r.Service)
+	}
+	if r.Region != "" {
+		tags = append(tags, "region:"+r.Region)
+	}
+	return tags
+}
+
+// Index 网段索引，按前缀长度分组，查询次数与不同前缀长度的数量相关
+// 构建后只读，可以并发使用
+type Index struct {
+	v4, v6 []level // 按前缀长度从长到短排序
+	size   int
+}
+
+type level struct {
+	bits   int
+	ranges map[netip.Prefix][]Range
+}
+
+// NewIndex 使用 ranges 构建索引
+func NewIndex(ranges ...[]Range) *Index {
+	v4 := make(map[int]map[netip.Prefix][]Range)
+	v6 := make(map[int]map[netip.Prefix][]Range)
+	var idx Index
+	for _, list := range ranges {
+		for _, r := range list {
+			if !r.Prefix.IsValid() {
+				continue
+			}
+			r.Prefix = r.Prefix.Masked()
+			g := v6
+			if r.Prefix.Addr().Is4() {
+				g = v4
+			}
+			bits := r.Prefix.Bits()
+			if g[bits] == nil {
+				g[bits] = make(map[netip.Prefix][]Range)
+			}
+			g[bits][r.Prefix] = append(g[bits][r.Prefix], r)
+			idx.size++
+		}
+	}
+	idx.v4, idx.v6 = toLevels(v4), toLevels(v6)
+	return &idx
+}
+
+func toLevels(g map[int]map[netip.Prefix][]Range) []level {
+	levels := make([]level, 0, len(g))
+	for bits, ranges := range g {
+		levels = append(levels, level{bits: bits, ranges: ranges})
+	}
+	slices.SortFunc(levels, func(x, y level) int { return y.bits - x.bits })
+	return levels
+}
+
+func (idx *Index) levels(addr netip.Addr) []level {
+	if addr.Is4() {
+		return idx.v4
+	}
+	return idx.v6
+}
+
+// Len 索引中的网段数量
+func (idx *Index) Len() int {
+	return idx.size
+}
+
+// Lookup 返回包含 addr 的所有网段，最长前缀在前，没有时返回 nil
+func (idx *Index) Lookup(addr netip.Addr) []Range {
+	addr = addr.Unmap()
+	var out []Range
+	for _, l := range idx.levels(addr) {
+		p, err := addr.Prefix(l.bits)
+		if err != nil {
+			continue
+		}
+		out = append(out, l.ranges[p]...)
+	}
+	return out
+}
+
+// Match 返回包含 addr 的最长前缀网段，同一网段有多条记录时返回第一条
+func (idx *Index) Match(addr netip.Addr) (Range, bool) {
+	addr = addr.Unmap()
+	for _, l := range idx.levels(addr) {
+		p, err := addr.Prefix(l.bits)
+		if err != nil {
+			continue
+		}
+		if r := l.ranges[p]; len(r) > 0 {
+			return r[0], true
+		}
+	}
+	return Range{}, false
+}
+
+// Tags 返回 ip 所属网段的全部标签，去重且最长前缀的标签在前
+func (idx *Index) Tags(ip string) []string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+	var tags []string
+	for _, r := range idx.Lookup(addr) {
+		for _, tag := range r.Tags() {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// Enrich implements geoip.Enricher, adds the tags of all matching ranges to Info.Tags
+func (idx *Index) Enrich(_ context.Context, ip string, info *geoip.Info) {
+	info.AddTags(idx.Tags(ip)...)
+}
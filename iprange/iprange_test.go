@@ -0,0 +1,201 @@
+package iprange
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+// testdata 中为各格式的示例文件，只包含少量网段
+func loadTestdata(t *testing.T) *Index {
+	t.Helper()
+	idx, err := LoadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestLoadTestdata(t *testing.T) {
+	idx := loadTestdata(t)
+	for ip, want := range map[string]string{
+		"3.80.1.1":               "aws",
+		"13.32.10.1":             "aws",
+		"2600:1f18::1":           "aws",
+		"34.80.1.1":              "gcp",
+		"20.185.3.4":             "azure",
+		"13.107.246.10":          "azure",
+		"47.74.1.1":              "alibaba",
+		"119.28.1.1":             "tencent",
+		"104.16.1.1":             "cloudflare",
+		"2606:4700::1111":        "cloudflare",
+		"::ffff:172.64.1.1":      "cloudflare",
+		"66.249.66.1":            "googlebot",
+		"2001:4860:4801:10::abc": "googlebot",
+		"157.55.39.1":            "bingbot",
+	} {
+		r, ok := idx.Match(netip.MustParseAddr(ip))
+		if !ok || r.Provider != want {
+			t.Fatalf("provider of %s not match, got: %+v", ip, r)
+		}
+	}
+	if _, ok := idx.Match(netip.MustParseAddr("8.8.8.8")); ok {
+		t.Fatal("expected no range for 8.8.8.8")
+	}
+	if ranges := idx.Lookup(netip.MustParseAddr("192.168.1.1")); ranges != nil {
+		t.Fatalf("expected nil, got: %+v", ranges)
+	}
+}
+
+func TestTags(t *testing.T) {
+	idx := loadTestdata(t)
+	tags := idx.Tags("3.80.1.1")
+	want := []string{"cloud", "provider:aws", "service:AMAZON", "region:us-east-1", "service:EC2"}
+	if !slices.Equal(tags, want) {
+		t.Fatalf("tags not match, got: %v", tags)
+	}
+	if tags := idx.Tags("66.249.66.1"); !slices.Equal(tags, []string{"bot", "provider:googlebot"}) {
+		t.Fatalf("bot tags not match, got: %v", tags)
+	}
+	if tags := idx.Tags("20.185.3.4"); !slices.Equal(tags, []string{"cloud", "provider:azure", "service:AzureCloud", "region:eastus"}) {
+		t.Fatalf("azure tags not match, got: %v", tags)
+	}
+	if tags := idx.Tags("bad"); tags != nil {
+		t.Fatalf("expected nil, got: %v", tags)
+	}
+
+	info := geoip.Info{IP: "104.16.1.1", Tags: []string{"cdn"}}
+	idx.Enrich(context.Background(), info.IP, &info)
+	if !slices.Equal(info.Tags, []string{"cdn", "provider:cloudflare"}) || !info.HasTag("provider:cloudflare") {
+		t.Fatalf("info tags not match, got: %v", info.Tags)
+	}
+}
+
+func TestLongestPrefix(t *testing.T) {
+	idx := NewIndex([]Range{
+		{Prefix: netip.MustParsePrefix("10.0.0.0/8"), Provider: "a", Kind: Cloud},
+		{Prefix: netip.MustParsePrefix("10.1.2.3/16"), Provider: "b", Kind: Cloud},
+		{Prefix: netip.MustParsePrefix("10.1.2.0/24"), Provider: "c", Kind: Cloud},
+	})
+	var got []string
+	for _, r := range idx.Lookup(netip.MustParseAddr("10.1.2.5")) {
+		got = append(got, r.Provider)
+	}
+	if !slices.Equal(got, []string{"c", "b", "a"}) || idx.Len() != 3 {
+		t.Fatalf("ranges not match, got: %v", got)
+	}
+	if r, _ := idx.Match(netip.MustParseAddr("10.1.9.9")); r.Provider != "b" {
+		t.Fatalf("match not match, got: %+v", r)
+	}
+}
+
+func TestParse(t *testing.T) {
+	ranges, err := Parse(strings.NewReader("# comment\n1.2.3.0/24\n\n::ffff:5.6.7.0/120 # mapped\n9.9.9.9\n"), FormatText, "x", CDN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range ranges {
+		got = append(got, r.Prefix.String())
+	}
+	if !slices.Equal(got, []string{"1.2.3.0/24", "5.6.7.0/24", "9.9.9.9/32"}) || ranges[0].Provider != "x" || ranges[0].Kind != CDN {
+		t.Fatalf("text ranges not match, got: %v", ranges)
+	}
+
+	for _, line := range []string{"1.2.3.0/33", "::ffff:1.2.3.0/64"} {
+		if _, err := Parse(strings.NewReader(line), FormatText, "x", CDN); err == nil {
+			t.Fatalf("expected error for invalid prefix %s", line)
+		}
+	}
+	if _, err := Parse(strings.NewReader("{}"), "yaml", "x", CDN); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	idx := Default()
+	if idx.Len() < 5000 {
+		t.Fatalf("snapshot not complete, got: %d", idx.Len())
+	}
+	for ip, want := range map[string]string{
+		"3.80.1.1":        "aws",
+		"52.94.76.1":      "aws",
+		"34.80.1.1":       "gcp",
+		"20.185.3.4":      "azure",
+		"104.16.1.1":      "cloudflare",
+		"2606:4700::1111": "cloudflare",
+	} {
+		r, ok := idx.Match(netip.MustParseAddr(ip))
+		if !ok || r.Provider != want {
+			t.Fatalf("provider of %s not match, got: %+v", ip, r)
+		}
+	}
+	// 快照不包含 service、region 与爬虫
+	if tags := idx.Tags("3.80.1.1"); !slices.Equal(tags, []string{"cloud", "provider:aws"}) {
+		t.Fatalf("snapshot tags not match, got: %v", tags)
+	}
+	if _, ok := idx.Match(netip.MustParseAddr("66.249.66.1")); ok {
+		t.Fatal("expected no bot ranges in snapshot")
+	}
+	if Default() != idx {
+		t.Fatal("expected the same index")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	if _, err := LoadDir(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist for missing dir, got: %v", err)
+	}
+
+	// 空目录使用内置快照
+	dir := t.TempDir()
+	idx, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.Len() != Default().Len() {
+		t.Fatalf("empty dir not match snapshot, got: %d", idx.Len())
+	}
+
+	aws := `{"prefixes": [{"ip_prefix": "8.8.8.0/24", "region": "test-1", "service": "EC2"}], "ipv6_prefixes": []}`
+	if err := os.WriteFile(filepath.Join(dir, "aws.json"), []byte(aws), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx, err = LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := idx.Match(netip.MustParseAddr("8.8.8.8")); !ok || r.Provider != "aws" || r.Region != "test-1" {
+		t.Fatalf("local range not match, got: %+v", r)
+	}
+	// 本地文件替代 AWS 的快照，其它服务商仍使用快照
+	if _, ok := idx.Match(netip.MustParseAddr("3.80.1.1")); ok {
+		t.Fatal("expected aws snapshot to be replaced")
+	}
+	if r, ok := idx.Match(netip.MustParseAddr("104.16.1.1")); !ok || r.Provider != "cloudflare" {
+		t.Fatalf("snapshot range not match, got: %+v", r)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "gcp.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), "gcp.json") {
+		t.Fatalf("expected parse error, got: %v", err)
+	}
+
+	idx, err = Load(os.DirFS("testdata"), Source{Provider: "cloudflare", Kind: CDN, Format: FormatText, File: "cloudflare-v4.txt"})
+	if err != nil || idx.Len() != 15 {
+		t.Fatalf("load not match, got: %v", err)
+	}
+	if _, err := Load(os.DirFS(dir), Source{Provider: "x", Format: FormatText, File: "missing.txt"}); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package iprange
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// Format 网段文件的格式
+type Format string
+
+const (
+	FormatAWS    Format = "aws"    // AWS ip-ranges.json
+	FormatGoogle Format = "google" // GCP cloud.json、googlebot.json、bingbot.json 等 Google 风格的 JSON
+	FormatAzure  Format = "azure"  // Azure ServiceTags_Public.json
+	FormatText   Format = "text"   // 每行一个 CIDR，如 Cloudflare ips-v4，# 开头为注释
+)
+
+// Parse 解析网段文件，provider 与 kind 写入每个 Range
+func Parse(r io.Reader, format Format, provider string, kind Kind) ([]Range, error) {
+	var (
+		out []Range
+		err error
+	)
+	switch format {
+	case FormatAWS:
+		out, err = parseAWS(r)
+	case FormatGoogle:
+		out, err = parseGoogle(r)
+	case FormatAzure:
+		out, err = parseAzure(r)
+	case FormatText:
+		out, err = parseText(r)
+	default:
+		return nil, fmt.Errorf("iprange: unknown format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("iprange: %s: %w", provider, err)
+	}
+	for i := range out {
+		out[i].Provider, out[i].Kind = provider, kind
+	}
+	return out, nil
+}
+
+// awsFile AWS ip-ranges.json
+//
+//	{
+//	    "syncToken": "1718236413",
+//	    "createDate": "2024-06-12-23-53-33",
+//	    "prefixes": [{"ip_prefix": "3.5.140.0/22", "region": "ap-northeast-2", "service": "S3", "network_border_group": "ap-northeast-2"}],
+//	    "ipv6_prefixes": [{"ipv6_prefix": "2600:1f18::/33", "region": "us-east-1", "service": "EC2", "network_border_group": "us-east-1"}]
+//	}
+type awsFile struct {
+	Prefixes []struct {
+		IPPrefix string `json:"ip_prefix"`
+		Region   string `json:"region"`
+		Service  string `json:"service"`
+	} `json:"prefixes"`
+	IPv6Prefixes []struct {
+		IPv6Prefix string `json:"ipv6_prefix"`
+		Region     string `json:"region"`
+		Service    string `json:"service"`
+	} `json:"ipv6_prefixes"`
+}
+
+func parseAWS(r io.Reader) ([]Range, error) {
+	var f awsFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	out := make([]Range, 0, len(f.Prefixes)+len(f.IPv6Prefixes))
+	for _, p := range f.Prefixes {
+		prefix, err := parsePrefix(p.IPPrefix)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Range{Prefix: prefix, Service: p.Service, Region: p.Region})
+	}
+	for _, p := range f.IPv6Prefixes {
+		prefix, err := parsePrefix(p.IPv6Prefix)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Range{Prefix: prefix, Service: p.Service, Region: p.Region})
+	}
+	return out, nil
+}
+
+// googleFile GCP cloud.json，googlebot.json 与 bingbot.json 没有 service 与 scope
+//
+//	{
+//	    "syncToken": "1718222579546",
+//	    "creationTime": "2024-06-12T13:02:59.546",
+//	    "prefixes": [{"ipv4Prefix": "34.80.0.0/15", "service": "Google Cloud", "scope": "asia-east1"}]
+//	}
+type googleFile struct {
+	Prefixes []struct {
+		IPv4Prefix string `json:"ipv4Prefix"`
+		IPv6Prefix string `json:"ipv6Prefix"`
+		Service    string `json:"service"`
+		Scope      string `json:"scope"`
+	} `json:"prefixes"`
+}
+
+func parseGoogle(r io.Reader) ([]Range, error) {
+	var f googleFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	out := make([]Range, 0, len(f.Prefixes))
+	for _, p := range f.Prefixes {
+		prefix, err := parsePrefix(p.IPv4Prefix + p.IPv6Prefix)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Range{Prefix: prefix, Service: p.Service, Region: p.Scope})
+	}
+	return out, nil
+}
+
+// azureFile Azure ServiceTags_Public.json
+//
+//	{
+//	    "changeNumber": 321,
+//	    "cloud": "Public",
+//	    "values": [{
+//	        "name": "AzureCloud.eastus",
+//	        "properties": {"region": "eastus", "systemService": "", "addressPrefixes": ["20.185.0.0/16"]}
+//	    }]
+//	}
+type azureFile struct {
+	Values []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Region          string   `json:"region"`
+			SystemService   string   `json:"systemService"`
+			AddressPrefixes []string `json:"addressPrefixes"`
+		} `json:"properties"`
+	} `json:"values"`
+}
+
+func parseAzure(r io.Reader) ([]Range, error) {
+	var f azureFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	var out []Range
+	for _, v := range f.Values {
+		// AzureCloud.eastus 等区域标签没有 systemService，使用名称中 . 之前的部分
+		service := v.Properties.SystemService
+		if service == "" {
+			service, _, _ = strings.Cut(v.Name, ".")
+		}
+		for _, s := range v.Properties.AddressPrefixes {
+			prefix, err := parsePrefix(s)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, Range{Prefix: prefix, Service: service, Region: v.Properties.Region})
+		}
+	}
+	return out, nil
+}
+
+func parseText(r io.Reader) ([]Range, error) {
+	var out []Range
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		prefix, err := parsePrefix(line)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Range{Prefix: prefix})
+	}
+	return out, sc.Err()
+}
+
+// parsePrefix 解析 CIDR，也接受单个地址
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() {
+		if prefix.Bits() < 96 {
+			return netip.Prefix{}, fmt.Errorf("ipv4-mapped prefix %s shorter than /96", s)
+		}
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
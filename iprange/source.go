@@ -0,0 +1,123 @@
+package iprange
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+)
+
+// snapshots 内置的完整网段快照，每行一个 CIDR，只区分服务商，不包含 service 与 region
+//
+//go:embed data/*.txt
+var snapshots embed.FS
+
+// Source 一个网段文件，从 URL 下载后以 File 为文件名保存
+type Source struct {
+	Provider string
+	Kind     Kind
+	Format   Format
+	File     string // 文件名，LoadDir 在目录中按该名称查找
+	URL      string // 官方发布地址，没有固定地址时为空，见 DefaultSources
+
+	snapshot string // data 中的内置快照，没有时为空
+}
+
+var defaultSources = []Source{
+	{Provider: "aws", Kind: Cloud, Format: FormatAWS, File: "aws.json", URL: "https://ip-ranges.amazonaws.com/ip-ranges.json", snapshot: "aws.txt"},
+	{Provider: "gcp", Kind: Cloud, Format: FormatGoogle, File: "gcp.json", URL: "https://www.gstatic.com/ipranges/cloud.json", snapshot: "gcp.txt"},
+	{Provider: "azure", Kind: Cloud, Format: FormatAzure, File: "azure.json", snapshot: "azure.txt"},
+	{Provider: "alibaba", Kind: Cloud, Format: FormatText, File: "alibaba.txt"},
+	{Provider: "tencent", Kind: Cloud, Format: FormatText, File: "tencent.txt"},
+	{Provider: "cloudflare", Kind: CDN, Format: FormatText, File: "cloudflare-v4.txt", URL: "https://www.cloudflare.com/ips-v4", snapshot: "cloudflare-v4.txt"},
+	{Provider: "cloudflare", Kind: CDN, Format: FormatText, File: "cloudflare-v6.txt", URL: "https://www.cloudflare.com/ips-v6", snapshot: "cloudflare-v6.txt"},
+	{Provider: "googlebot", Kind: Bot, Format: FormatGoogle, File: "googlebot.json", URL: "https://developers.google.com/static/search/apis/ipranges/googlebot.json"},
+	{Provider: "bingbot", Kind: Bot, Format: FormatGoogle, File: "bingbot.json", URL: "https://www.bing.com/toolbox/bingbot.json"},
+}
+
+// DefaultSources 返回 LoadDir 使用的网段文件列表
+// 阿里云与腾讯云没有官方文件，需要自行维护每行一个 CIDR 的列表
+// Azure 的 ServiceTags_Public_日期.json 每周发布一次且文件名随之变化，需要从
+// https://www.microsoft.com/en-us/download/details.aspx?id=56519 获取下载地址，保存为 azure.json
+func DefaultSources() []Source {
+	return slices.Clone(defaultSources)
+}
+
+var defaultIndex = sync.OnceValue(func() *Index {
+	ranges := make([][]Range, 0, len(defaultSources))
+	for _, src := range defaultSources {
+		if src.snapshot == "" {
+			continue
+		}
+		list, err := loadSnapshot(src)
+		if err != nil {
+			panic("iprange: invalid embedded snapshot: " + err.Error())
+		}
+		ranges = append(ranges, list)
+	}
+	return NewIndex(ranges...)
+})
+
+// Default 返回使用内置快照构建的索引，包含 AWS、GCP、Azure 与 Cloudflare 的全部网段
+// 快照只区分服务商，标签为 cloud、provider:aws 等，没有 service 与 region；爬虫、阿里云与腾讯云需要通过 LoadDir 加载
+func Default() *Index {
+	return defaultIndex()
+}
+
+// Load 从 fsys 中读取 sources 并构建索引
+func Load(fsys fs.FS, sources ...Source) (*Index, error) {
+	ranges := make([][]Range, 0, len(sources))
+	for _, src := range sources {
+		list, err := loadSource(fsys, src)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, list)
+	}
+	return NewIndex(ranges...), nil
+}
+
+// LoadDir 从本地目录读取 DefaultSources 中的文件，文件名与 Source.File 一致
+// 目录中的文件替代对应的内置快照；不存在的文件使用内置快照，没有快照时跳过
+// 例如只下载了 aws.json 与 googlebot.json 时，GCP、Azure 与 Cloudflare 使用内置快照
+func LoadDir(dir string) (*Index, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("iprange: %w", err)
+	}
+	fsys := os.DirFS(dir)
+	ranges := make([][]Range, 0, len(defaultSources))
+	for _, src := range defaultSources {
+		list, err := loadSource(fsys, src)
+		if errors.Is(err, fs.ErrNotExist) {
+			if src.snapshot == "" {
+				continue
+			}
+			list, err = loadSnapshot(src)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, list)
+	}
+	return NewIndex(ranges...), nil
+}
+
+func loadSource(fsys fs.FS, src Source) ([]Range, error) {
+	f, err := fsys.Open(src.File)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	list, err := Parse(f, src.Format, src.Provider, src.Kind)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, src.File)
+	}
+	return list, nil
+}
+
+func loadSnapshot(src Source) ([]Range, error) {
+	return loadSource(snapshots, Source{Provider: src.Provider, Kind: src.Kind, Format: FormatText, File: "data/" + src.snapshot})
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrustedProxies 解析受信任的代理，支持 CIDR 与单个 IP
+func ParseTrustedProxies(s ...string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(s))
+	for _, v := range s {
+		v = strings.TrimSpace(v)
+		if strings.Contains(v, "/") {
+			p, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+			}
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+		}
+		addr = addr.Unmap()
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out, nil
+}
+
+// ipExtractor 根据受信任的代理提取客户端 IP
+type ipExtractor struct {
+	trusted []netip.Prefix
+}
+
+func (x *ipExtractor) isTrusted(addr netip.Addr) bool {
+	for _, p := range x.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 直连地址不受信任时直接返回
+// 否则按 Forwarded、X-Forwarded-For 的顺序从右向左跳过受信任的代理，返回第一个不受信任的地址
+// 两者都没有时使用 X-Real-IP，遇到 unknown、混淆的标识或空的节点时返回零值
+func (x *ipExtractor) clientIP(r *http.Request) netip.Addr {
+	remote := parseHostAddr(r.RemoteAddr)
+	if !remote.IsValid() || !x.isTrusted(remote) {
+		return remote
+	}
+
+	var hops []string
+	if values := r.Header.Values("Forwarded"); len(values) > 0 {
+		hops = forwardedFor(values)
+	} else if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		for _, v := range values {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+	}
+	if len(hops) == 0 {
+		if addr := parseHostAddr(r.Header.Get("X-Real-IP")); addr.IsValid() {
+			return addr
+		}
+		return remote
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr := parseHostAddr(hops[i])
+		// unknown 或混淆的标识无法继续追溯，可信代理的地址不是客户端地址
+		if !addr.IsValid() {
+			return netip.Addr{}
+		}
+		client = addr
+		if !x.isTrusted(addr) {
+			break
+		}
+	}
+	return client
+}
+
+// forwardedFor 返回 RFC 7239 Forwarded 头中各节点的 for 参数
+//
+//	Forwarded: for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"
+func forwardedFor(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for elem := range strings.SplitSeq(v, ",") {
+			found := ""
+			for pair := range strings.SplitSeq(elem, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					found = strings.Trim(val, `"`)
+				}
+			}
+			out = append(out, found)
+		}
+	}
+	return out
+}
+
+// parseHostAddr 解析 IP、IP:port、[IPv6]:port，失败时返回零值
+func parseHostAddr(s string) netip.Addr {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return netip.Addr{}
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(s, "[]")); err == nil {
+		return addr.Unmap()
+	}
+	host, _, err := net.SplitHostPort(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
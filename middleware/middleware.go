@@ -0,0 +1,143 @@
+// Package middleware 提供 net/http 中间件，提取客户端 IP 并通过 geoip 查询地理位置
+//
+//	engine := geoip.New(geoip.Chinese)
+//	proxies, _ := middleware.ParseTrustedProxies("10.0.0.0/8")
+//	handler := middleware.New(engine, middleware.WithTrustedProxies(proxies...), middleware.WithLazy())(mux)
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//		info, err := middleware.Info(r.Context())
+//	}
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/netip"
+	"sync"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+// ErrNoClientIP 无法从请求中提取客户端 IP
+var ErrNoClientIP = errors.New("no client ip")
+
+// Mode 查询时机
+type Mode int
+
+const (
+	Sync  Mode = iota // 调用下一个 handler 之前查询
+	Async             // 在后台查询，Info 等待查询完成
+	Lazy              // 首次调用 Info 时查询
+)
+
+type config struct {
+	extractor ipExtractor
+	mode      Mode
+}
+
+type Option func(*config)
+
+// WithTrustedProxies 受信任的代理，只有直连地址受信任时才会读取 Forwarded/X-Forwarded-For/X-Real-IP
+// 默认不信任任何代理，客户端 IP 为 RemoteAddr
+func WithTrustedProxies(prefixes ...netip.Prefix) Option {
+	return func(c *config) {
+		c.extractor.trusted = append(c.extractor.trusted, prefixes...)
+	}
+}
+
+// WithAsync 在后台查询，不阻塞请求处理
+func WithAsync() Option {
+	return func(c *config) {
+		c.mode = Async
+	}
+}
+
+// WithLazy 首次调用 Info 时才查询，没有使用地理位置的请求不会产生查询
+func WithLazy() Option {
+	return func(c *config) {
+		c.mode = Lazy
+	}
+}
+
+// result 存放在请求 context 中的查询结果
+type result struct {
+	ip     netip.Addr
+	lookup func() (*geoip.Info, error)
+
+	once sync.Once
+	done chan struct{} // Async 模式下查询完成时关闭
+	info *geoip.Info
+	err  error
+}
+
+func (r *result) resolve() {
+	r.once.Do(func() {
+		r.info, r.err = r.lookup()
+	})
+}
+
+type ctxKey struct{}
+
+// New 创建中间件，iper 通常为 *geoip.Engine，也可以是 RangeDB 等任意 geoip.IPer
+func New(iper geoip.IPer, opts ...Option) func(http.Handler) http.Handler {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			res := result{ip: c.extractor.clientIP(r)}
+			res.lookup = func() (*geoip.Info, error) {
+				if !res.ip.IsValid() {
+					return nil, ErrNoClientIP
+				}
+				return iper.Lookup(ctx, res.ip.String())
+			}
+			switch c.mode {
+			case Sync:
+				res.resolve()
+			case Async:
+				res.done = make(chan struct{})
+				go func() {
+					defer close(res.done)
+					res.resolve()
+				}()
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, ctxKey{}, &res)))
+		})
+	}
+}
+
+func fromContext(ctx context.Context) *result {
+	res, _ := ctx.Value(ctxKey{}).(*result)
+	return res
+}
+
+// ClientIP 返回中间件提取的客户端 IP
+func ClientIP(ctx context.Context) (netip.Addr, bool) {
+	res := fromContext(ctx)
+	if res == nil || !res.ip.IsValid() {
+		return netip.Addr{}, false
+	}
+	return res.ip, true
+}
+
+// Info 返回客户端 IP 的地理位置，Async 模式下等待查询完成，Lazy 模式下首次调用时查询
+// 请求没有经过中间件或无法提取客户端 IP 时返回 ErrNoClientIP
+func Info(ctx context.Context) (*geoip.Info, error) {
+	res := fromContext(ctx)
+	if res == nil {
+		return nil, ErrNoClientIP
+	}
+	if res.done != nil {
+		select {
+		case <-res.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	res.resolve()
+	return res.info, res.err
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8", "2001:db8::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := ipExtractor{trusted: trusted}
+
+	cases := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"untrusted remote", "203.0.113.7:1234", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.7"},
+		{"xff", "10.0.0.1:80", map[string]string{"X-Forwarded-For": "1.2.3.4, 5.6.7.8, 10.0.0.2"}, "5.6.7.8"},
+		{"xff all trusted", "10.0.0.1:80", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"real ip", "10.0.0.1:80", map[string]string{"X-Real-IP": "1.2.3.4"}, "1.2.3.4"},
+		{"forwarded", "[2001:db8::1]:443", map[string]string{
+			"Forwarded":       `for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711";by=10.0.0.9`,
+			"X-Forwarded-For": "9.9.9.9",
+		}, "2001:db8:cafe::17"},
+		{"forwarded unknown", "10.0.0.1:80", map[string]string{"Forwarded": "for=unknown, for=10.0.0.5"}, "invalid IP"},
+		{"forwarded obfuscated", "10.0.0.1:80", map[string]string{"Forwarded": "for=_hidden, for=10.0.0.5"}, "invalid IP"},
+		{"xff empty hop", "10.0.0.1:80", map[string]string{"X-Forwarded-For": "1.2.3.4, , 10.0.0.2"}, "invalid IP"},
+		{"forwarded unknown behind client", "10.0.0.1:80", map[string]string{"Forwarded": "for=unknown, for=1.2.3.4, for=10.0.0.5"}, "1.2.3.4"},
+		{"no headers", "10.0.0.1:80", nil, "10.0.0.1"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = c.remote
+		for k, v := range c.headers {
+			r.Header.Set(k, v)
+		}
+		if got := x.clientIP(r); got.String() != c.want {
+			t.Fatalf("%s not match, got: %s", c.name, got)
+		}
+	}
+
+	if _, err := ParseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Fatal("expected invalid proxy error")
+	}
+}
+
+type countIPer struct {
+	calls atomic.Int32
+}
+
+func (c *countIPer) Lookup(_ context.Context, ip string) (*geoip.Info, error) {
+	c.calls.Add(1)
+	if ip == "192.0.2.1" {
+		return nil, errors.New("lookup failed")
+	}
+	return &geoip.Info{IP: ip, Country: "中国"}, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	for _, mode := range []Option{func(*config) {}, WithAsync(), WithLazy()} {
+		var iper countIPer
+		var got *geoip.Info
+		var gotErr error
+		handler := New(&iper, mode)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, ok := ClientIP(r.Context()); !ok || ip.String() != "183.95.1.1" {
+				t.Errorf("client ip not match, got: %s", ip)
+			}
+			got, gotErr = Info(r.Context())
+			Info(r.Context())
+		}))
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "183.95.1.1:5678"
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		if gotErr != nil || got == nil || got.Country != "中国" {
+			t.Fatalf("info not match, got: %+v %v", got, gotErr)
+		}
+		if n := iper.calls.Load(); n != 1 {
+			t.Fatalf("lookup calls not match, got: %d", n)
+		}
+	}
+
+	// 未使用地理位置时 Lazy 模式不查询
+	var iper countIPer
+	handler := New(&iper, WithLazy())(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if n := iper.calls.Load(); n != 0 {
+		t.Fatalf("lazy lookup calls not match, got: %d", n)
+	}
+
+	// 可信代理之前的节点为 unknown 时不使用代理的地址查询
+	trusted, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler = New(&iper, WithTrustedProxies(trusted...))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := ClientIP(r.Context()); ok {
+			t.Errorf("expected no client ip, got: %s", ip)
+		}
+		if _, err := Info(r.Context()); !errors.Is(err, ErrNoClientIP) {
+			t.Errorf("expected ErrNoClientIP, got: %v", err)
+		}
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:80"
+	r.Header.Set("Forwarded", "for=unknown, for=10.0.0.5")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if n := iper.calls.Load(); n != 0 {
+		t.Fatalf("unknown hop lookup calls not match, got: %d", n)
+	}
+
+	if _, err := Info(context.Background()); !errors.Is(err, ErrNoClientIP) {
+		t.Fatalf("expected ErrNoClientIP, got: %v", err)
+	}
+}
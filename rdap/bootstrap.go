@@ -0,0 +1,171 @@
+package rdap
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// IANA 发布的 RDAP bootstrap 文件，参见 RFC 9224
+const (
+	IANAIPv4 = "https://data.iana.org/rdap/ipv4.json"
+	IANAIPv6 = "https://data.iana.org/rdap/ipv6.json"
+)
+
+// 内置的 bootstrap 文件，RIR 之间的分配变化很少，可以通过 Client.Refresh 更新
+var (
+	//go:embed data/ipv4.json
+	ipv4JSON []byte
+	//go:embed data/ipv6.json
+	ipv6JSON []byte
+)
+
+var defaultBootstrap = sync.OnceValue(func() *Bootstrap {
+	b, err := ParseBootstrap(ipv4JSON, ipv6JSON)
+	if err != nil {
+		panic("rdap: invalid embedded bootstrap: " + err.Error())
+	}
+	return b
+})
+
+// DefaultBootstrap 返回内置的 bootstrap
+func DefaultBootstrap() *Bootstrap {
+	return defaultBootstrap()
+}
+
+// bootstrapFile IANA bootstrap 文件
+//
+//	{
+//	    "version": "1.0",
+//	    "publication": "2025-06-11T18:00:02Z",
+//	    "services": [
+//	        [["41.0.0.0/8", "102.0.0.0/8"], ["https://rdap.afrinic.net/rdap/"]]
+//	    ]
+//	}
+type bootstrapFile struct {
+	Publication string       `json:"publication"`
+	Services    [][][]string `json:"services"`
+}
+
+type bootstrapEntry struct {
+	prefix netip.Prefix
+	urls   []string
+}
+
+// Bootstrap IP 段到 RDAP 服务地址的映射
+type Bootstrap struct {
+	Publication string           // 最新的文件发布时间
+	entries     []bootstrapEntry // 按前缀长度从长到短排序
+}
+
+// ParseBootstrap 解析一个或多个 bootstrap 文件，通常为 ipv4.json 与 ipv6.json
+func ParseBootstrap(files ...[]byte) (*Bootstrap, error) {
+	var b Bootstrap
+	for _, data := range files {
+		var f bootstrapFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("rdap: bootstrap: %w", err)
+		}
+		b.Publication = max(b.Publication, f.Publication)
+		for _, svc := range f.Services {
+			if len(svc) != 2 || len(svc[1]) == 0 {
+				return nil, fmt.Errorf("rdap: bootstrap: invalid service %q", svc)
+			}
+			for _, s := range svc[0] {
+				prefix, err := netip.ParsePrefix(s)
+				if err != nil {
+					return nil, fmt.Errorf("rdap: bootstrap: %w", err)
+				}
+				b.entries = append(b.entries, bootstrapEntry{prefix: prefix.Masked(), urls: svc[1]})
+			}
+		}
+	}
+	if len(b.entries) == 0 {
+		return nil, fmt.Errorf("rdap: bootstrap: no services")
+	}
+	slices.SortStableFunc(b.entries, func(x, y bootstrapEntry) int { return y.prefix.Bits() - x.prefix.Bits() })
+	return &b, nil
+}
+
+// LoadBootstrap 从 r 中读取 bootstrap 文件
+func LoadBootstrap(r ...io.Reader) (*Bootstrap, error) {
+	files := make([][]byte, len(r))
+	for i := range r {
+		data, err := io.ReadAll(r[i])
+		if err != nil {
+			return nil, err
+		}
+		files[i] = data
+	}
+	return ParseBootstrap(files...)
+}
+
+// FetchBootstrap 从 IANA 下载最新的 bootstrap 文件，client 为 nil 时使用 http.DefaultClient
+func FetchBootstrap(ctx context.Context, client *http.Client) (*Bootstrap, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	files := make([][]byte, 0, 2)
+	for _, link := range []string{IANAIPv4, IANAIPv6} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("rdap: fetch %s: status code: %d", link, resp.StatusCode)
+		}
+		files = append(files, data)
+	}
+	return ParseBootstrap(files...)
+}
+
+// Lookup 返回负责 addr 的 RDAP 服务地址，优先使用 https，地址以 / 结尾
+func (b *Bootstrap) Lookup(addr netip.Addr) (string, bool) {
+	addr = addr.Unmap()
+	for _, e := range b.entries {
+		if !e.prefix.Contains(addr) {
+			continue
+		}
+		link := e.urls[0]
+		for _, u := range e.urls {
+			if strings.HasPrefix(u, "https://") {
+				link = u
+				break
+			}
+		}
+		if !strings.HasSuffix(link, "/") {
+			link += "/"
+		}
+		return link, true
+	}
+	return "", false
+}
+
+// registryName 根据服务地址返回 RIR 名称，与 Team Cymru 的命名一致
+func registryName(link string) string {
+	for _, name := range []string{"afrinic", "apnic", "arin", "lacnic"} {
+		if strings.Contains(link, name) {
+			return name
+		}
+	}
+	if strings.Contains(link, "ripe") {
+		return "ripencc"
+	}
+	return ""
+}
@@ -0,0 +1,302 @@
+package rdap
+
+import (
+	"encoding/json"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// Network RDAP ip network 对象
+type Network struct {
+	Handle       string         // 注册编号，如 NET-8-8-8-0-2
+	Name         string         // 网络名称，如 GOGL
+	Type         string         // 分配类型，如 DIRECT ALLOCATION
+	Country      string         // ISO 3166-1 alpha-2，ARIN 等不返回
+	StartAddress string         // 起始地址
+	EndAddress   string         // 结束地址
+	CIDRs        []netip.Prefix // 网段，没有 cidr0 扩展时由起止地址计算
+	ParentHandle string         // 上级网段的注册编号
+	Registry     string         // 应答的 RIR，如 arin、ripencc
+	Entities     []Entity       // 相关联系人，可能嵌套
+	Remarks      []string       // 备注
+}
+
+// Entity RDAP 联系人，信息来自 vCard
+type Entity struct {
+	Handle   string
+	Roles    []string // registrant、administrative、technical、abuse 等
+	Kind     string   // individual、org、group
+	Name     string   // vCard fn
+	Org      string   // vCard org
+	Emails   []string
+	Phones   []string
+	Address  string // vCard adr 的 label，没有时为各部分以逗号连接
+	Country  string // vCard adr 中的国家，可能是名称或代码
+	Entities []Entity
+}
+
+// HasRole 是否具有该角色
+func (e *Entity) HasRole(role string) bool {
+	return slices.ContainsFunc(e.Roles, func(r string) bool { return strings.EqualFold(r, role) })
+}
+
+// FindEntity 深度优先查找第一个具有该角色的联系人
+func (n *Network) FindEntity(role string) *Entity {
+	return findEntity(n.Entities, role)
+}
+
+func findEntity(entities []Entity, role string) *Entity {
+	for i := range entities {
+		if entities[i].HasRole(role) {
+			return &entities[i]
+		}
+	}
+	for i := range entities {
+		if e := findEntity(entities[i].Entities, role); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// Abuse 返回滥用投诉联系人，没有时返回 nil
+func (n *Network) Abuse() *Entity {
+	return n.FindEntity("abuse")
+}
+
+// Registrant 返回网段的注册人，没有时返回 nil
+func (n *Network) Registrant() *Entity {
+	return n.FindEntity("registrant")
+}
+
+// rawNetwork RDAP ip network 应答，参见 RFC 9083
+//
+//	{
+//	    "objectClassName": "ip network",
+//	    "handle": "NET-8-8-8-0-2",
+//	    "startAddress": "8.8.8.0",
+//	    "endAddress": "8.8.8.255",
+//	    "name": "GOGL",
+//	    "type": "DIRECT ALLOCATION",
+//	    "parentHandle": "NET-8-0-0-0-0",
+//	    "cidr0_cidrs": [{"v4prefix": "8.8.8.0", "length": 24}],
+//	    "entities": [{"handle": "GOGL", "roles": ["registrant"], "vcardArray": ["vcard", [...]]}]
+//	}
+type rawNetwork struct {
+	Handle       string      `json:"handle"`
+	StartAddress string      `json:"startAddress"`
+	EndAddress   string      `json:"endAddress"`
+	Name         string      `json:"name"`
+	Type         string      `json:"type"`
+	Country      string      `json:"country"`
+	ParentHandle string      `json:"parentHandle"`
+	CIDRs        []rawCIDR   `json:"cidr0_cidrs"`
+	Entities     []rawEntity `json:"entities"`
+	Remarks      []struct {
+		Title       string   `json:"title"`
+		Description []string `json:"description"`
+	} `json:"remarks"`
+}
+
+type rawCIDR struct {
+	V4Prefix string `json:"v4prefix"`
+	V6Prefix string `json:"v6prefix"`
+	Length   int    `json:"length"`
+}
+
+type rawEntity struct {
+	Handle     string          `json:"handle"`
+	Roles      []string        `json:"roles"`
+	VCardArray json.RawMessage `json:"vcardArray"`
+	Entities   []rawEntity     `json:"entities"`
+}
+
+func (r *rawNetwork) toNetwork() *Network {
+	n := Network{
+		Handle:       r.Handle,
+		Name:         r.Name,
+		Type:         r.Type,
+		Country:      strings.ToUpper(r.Country),
+		StartAddress: r.StartAddress,
+		EndAddress:   r.EndAddress,
+		ParentHandle: r.ParentHandle,
+		Entities:     toEntities(r.Entities),
+	}
+	for _, c := range r.CIDRs {
+		addr, err := netip.ParseAddr(c.V4Prefix + c.V6Prefix)
+		if err != nil {
+			continue
+		}
+		if p, err := addr.Prefix(c.Length); err == nil {
+			n.CIDRs = append(n.CIDRs, p)
+		}
+	}
+	if len(n.CIDRs) == 0 {
+		start, err1 := netip.ParseAddr(r.StartAddress)
+		end, err2 := netip.ParseAddr(r.EndAddress)
+		if err1 == nil && err2 == nil {
+			n.CIDRs = rangeToPrefixes(start, end)
+		}
+	}
+	for _, rm := range r.Remarks {
+		if text := strings.Join(rm.Description, "\n"); text != "" {
+			n.Remarks = append(n.Remarks, text)
+		}
+	}
+	return &n
+}
+
+func toEntities(raw []rawEntity) []Entity {
+	if len(raw) == 0 {
+		return nil
+	}
+	out := make([]Entity, len(raw))
+	for i, r := range raw {
+		out[i] = Entity{Handle: r.Handle, Roles: r.Roles, Entities: toEntities(r.Entities)}
+		parseVCard(r.VCardArray, &out[i])
+	}
+	return out
+}
+
+// parseVCard 解析 jCard，参见 RFC 7095
+//
+//	["vcard", [
+//	    ["version", {}, "text", "4.0"],
+//	    ["fn", {}, "text", "Abuse"],
+//	    ["adr", {"label": "1600 Amphitheatre Parkway\nMountain View\nCA\n94043\nUnited States"}, "text", ["", "", "", "", "", "", ""]],
+//	    ["email", {}, "text", "network-abuse@google.com"],
+//	    ["tel", {"type": ["work", "voice"]}, "text", "+1-650-253-0000"]
+//	]]
+func parseVCard(data json.RawMessage, e *Entity) {
+	var card []json.RawMessage
+	if len(data) == 0 || json.Unmarshal(data, &card) != nil || len(card) != 2 {
+		return
+	}
+	var props [][]json.RawMessage
+	if json.Unmarshal(card[1], &props) != nil {
+		return
+	}
+	for _, prop := range props {
+		if len(prop) < 4 {
+			continue
+		}
+		var name string
+		if json.Unmarshal(prop[0], &name) != nil {
+			continue
+		}
+		var params struct {
+			Label string `json:"label"`
+		}
+		_ = json.Unmarshal(prop[1], &params)
+		values := vcardValues(prop[3:])
+
+		switch strings.ToLower(name) {
+		case "fn":
+			e.Name = first(values)
+		case "kind":
+			e.Kind = first(values)
+		case "org":
+			e.Org = first(values)
+		case "email":
+			if v := first(values); v != "" {
+				e.Emails = append(e.Emails, v)
+			}
+		case "tel":
+			if v := strings.TrimPrefix(first(values), "tel:"); v != "" {
+				e.Phones = append(e.Phones, v)
+			}
+		case "adr":
+			e.Address = strings.ReplaceAll(params.Label, "\n", ", ")
+			// 结构化地址的最后一项为国家
+			if len(values) == 7 {
+				if e.Address == "" {
+					e.Address = joinNonEmpty(", ", values...)
+				}
+				e.Country = values[6]
+			}
+			if e.Country == "" && params.Label != "" {
+				lines := strings.Split(strings.TrimSpace(params.Label), "\n")
+				e.Country = strings.TrimSpace(lines[len(lines)-1])
+			}
+		}
+	}
+}
+
+// vcardValues 展开属性值，结构化的值为数组，数组中的元素也可能是数组
+func vcardValues(raw []json.RawMessage) []string {
+	var out []string
+	for _, r := range raw {
+		var s string
+		if json.Unmarshal(r, &s) == nil {
+			out = append(out, s)
+			continue
+		}
+		var list []json.RawMessage
+		if json.Unmarshal(r, &list) == nil {
+			for _, item := range list {
+				var v string
+				if json.Unmarshal(item, &v) != nil {
+					var parts []string
+					_ = json.Unmarshal(item, &parts)
+					v = strings.Join(parts, " ")
+				}
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+// rangeToPrefixes 返回覆盖 [start, end] 的最少网段
+func rangeToPrefixes(start, end netip.Addr) []netip.Prefix {
+	if start.BitLen() != end.BitLen() || end.Less(start) {
+		return nil
+	}
+	var out []netip.Prefix
+	for {
+		bits := start.BitLen()
+		// 以 start 对齐且不超过 end 的最大网段
+		for bits > 0 {
+			p, _ := start.Prefix(bits - 1)
+			if p.Addr() != start || lastAddr(p).Compare(end) > 0 {
+				break
+			}
+			bits--
+		}
+		p := netip.PrefixFrom(start, bits)
+		out = append(out, p)
+		last := lastAddr(p)
+		if last.Compare(end) >= 0 {
+			return out
+		}
+		start = last.Next()
+	}
+}
+
+// lastAddr 返回网段的最后一个地址
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
@@ -0,0 +1,167 @@
+// Package rdap 通过 RDAP 查询 IP 段的注册信息，包括网络名称、所属组织、国家与滥用投诉联系方式
+//
+// 查询的 RIR 由 IANA bootstrap 文件决定，内置一份快照，可以通过 Client.Refresh 更新
+//
+//	c := rdap.NewClient()
+//	network, err := c.LookupIP(ctx, "8.8.8.8")
+//	abuse := network.Abuse()
+//
+// Client 同时实现了 geoip.IPer，可以作为国家信息的兜底
+//
+//	engine := geoip.New(geoip.English, geoip.WithHandlers(geoip.NewIPapi(), rdap.NewClient()))
+//
+// 导入该包后，也可以在 geoip 配置中使用名称 rdap
+package rdap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+// ErrNoServer bootstrap 中没有负责该 IP 的 RDAP 服务
+var ErrNoServer = errors.New("rdap: no server for ip")
+
+func init() {
+	geoip.Register(geoip.Provider{
+		Name:        "rdap",
+		Description: "RDAP registration data from the regional internet registries, country and network owner only",
+		Languages:   []geoip.Language{geoip.English},
+		New:         func(geoip.Credentials) (geoip.IPer, error) { return NewClient(), nil },
+	})
+}
+
+// Client RDAP 客户端
+type Client struct {
+	HTTPClient *http.Client // 为 nil 时使用 10 秒超时的默认客户端
+
+	mu        sync.RWMutex
+	bootstrap *Bootstrap
+}
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewClient 使用内置的 bootstrap 创建客户端
+func NewClient() *Client {
+	return &Client{bootstrap: DefaultBootstrap()}
+}
+
+// Bootstrap 返回当前使用的 bootstrap
+func (c *Client) Bootstrap() *Bootstrap {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bootstrap
+}
+
+// SetBootstrap 替换 bootstrap，例如使用本地缓存的 IANA 文件
+func (c *Client) SetBootstrap(b *Bootstrap) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bootstrap = b
+}
+
+// Refresh 从 IANA 下载最新的 bootstrap，失败时保留当前的 bootstrap
+func (c *Client) Refresh(ctx context.Context) error {
+	b, err := FetchBootstrap(ctx, c.httpClient())
+	if err != nil {
+		return err
+	}
+	c.SetBootstrap(b)
+	return nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
+// LookupIP 查询 ip 所在网段的注册信息
+func (c *Client) LookupIP(ctx context.Context, ip string) (*Network, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", geoip.ErrInvalidIP, ip)
+	}
+	base, ok := c.Bootstrap().Lookup(addr)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoServer, ip)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"ip/"+addr.Unmap().String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rdap: %w", geoip.NewStatusError(resp))
+	}
+
+	var raw rawNetwork
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("rdap: %w", err)
+	}
+	n := raw.toNetwork()
+	// 跟随重定向后使用实际应答的 RIR
+	n.Registry = registryName(resp.Request.URL.String())
+	if n.Registry == "" {
+		n.Registry = registryName(base)
+	}
+	return n, nil
+}
+
+// Lookup implements geoip.IPer, fills country, network owner, prefix, registry and abuse contact
+func (c *Client) Lookup(ctx context.Context, ip string) (*geoip.Info, error) {
+	n, err := c.LookupIP(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+	return n.toInfo(ip), nil
+}
+
+func (n *Network) toInfo(ip string) *geoip.Info {
+	info := geoip.Info{
+		IP:          ip,
+		CountryCode: n.Country,
+		Org:         n.Name,
+		Registry:    n.Registry,
+	}
+	if len(n.CIDRs) > 0 {
+		info.Prefix = n.CIDRs[0].String()
+	}
+	if e := n.Registrant(); e != nil {
+		if e.Name != "" {
+			info.Org = e.Name
+		}
+		// ARIN 等不返回网段的国家，使用注册人地址中的国家
+		if info.CountryCode == "" {
+			info.CountryCode = geoip.NormalizeCountry(e.Country)
+		}
+	}
+	info.ISP = info.Org
+	if e := n.Abuse(); e != nil {
+		abuse := geoip.Abuse{Name: e.Name, Address: e.Address, Country: geoip.NormalizeCountry(e.Country), Network: info.Prefix}
+		if len(e.Emails) > 0 {
+			abuse.Email = e.Emails[0]
+		}
+		if len(e.Phones) > 0 {
+			abuse.Phone = e.Phones[0]
+		}
+		info.Abuse = &abuse
+	}
+	return &info
+}
@@ -0,0 +1,211 @@
+package rdap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ixugo/netpulse/geoip"
+)
+
+const arinNetwork = `{
+	"objectClassName": "ip network",
+	"handle": "NET-8-8-8-0-2",
+	"startAddress": "8.8.8.0",
+	"endAddress": "8.8.8.255",
+	"ipVersion": "v4",
+	"name": "GOGL",
+	"type": "DIRECT ALLOCATION",
+	"parentHandle": "NET-8-0-0-0-0",
+	"cidr0_cidrs": [{"v4prefix": "8.8.8.0", "length": 24}],
+	"entities": [{
+		"handle": "GOGL",
+		"roles": ["registrant"],
+		"vcardArray": ["vcard", [
+			["version", {}, "text", "4.0"],
+			["fn", {}, "text", "Google LLC"],
+			["adr", {"label": "1600 Amphitheatre Parkway\nMountain View\nCA\n94043\nUnited States"}, "text", ["", "", "", "", "", "", ""]],
+			["kind", {}, "text", "org"]
+		]],
+		"entities": [{
+			"handle": "ABUSE5250-ARIN",
+			"roles": ["abuse"],
+			"vcardArray": ["vcard", [
+				["version", {}, "text", "4.0"],
+				["fn", {}, "text", "Abuse"],
+				["kind", {}, "text", "group"],
+				["email", {}, "text", "network-abuse@google.com"],
+				["tel", {"type": ["work", "voice"]}, "text", "+1-650-253-0000"]
+			]]
+		}]
+	}]
+}`
+
+const ripeNetwork = `{
+	"objectClassName": "ip network",
+	"handle": "193.0.0.0 - 193.0.7.255",
+	"startAddress": "193.0.0.0",
+	"endAddress": "193.0.7.255",
+	"name": "RIPE-NCC",
+	"country": "nl",
+	"remarks": [{"description": ["Used for RIPE NCC infrastructure."]}],
+	"entities": [{
+		"handle": "OPS4-RIPE",
+		"roles": ["abuse"],
+		"vcardArray": ["vcard", [
+			["version", {}, "text", "4.0"],
+			["fn", {}, "text", "RIPE NCC Operations"],
+			["adr", {}, "text", ["", "", "Stationsplein 11", "Amsterdam", "", "1012 AB", "NL"]],
+			["email", {}, "text", "abuse@ripe.net"]
+		]]
+	}]
+}`
+
+func newTestClient(t *testing.T) *Client {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/arin/ip/8.8.8.8":
+			fmt.Fprint(w, arinNetwork)
+		case "/ripe/ip/193.0.6.139":
+			fmt.Fprint(w, ripeNetwork)
+		case "/ripe/ip/193.0.0.1":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/ripe/ip/193.0.0.2":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	// 优先使用 https，补全末尾的 /
+	b, err := ParseBootstrap([]byte(fmt.Sprintf(`{"services": [
+		[["8.0.0.0/8"], ["http://unused.example/", "%[1]s/arin"]],
+		[["193.0.0.0/8"], ["%[1]s/ripe/"]]
+	]}`, ts.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient()
+	c.HTTPClient = ts.Client()
+	c.SetBootstrap(b)
+	return c
+}
+
+func TestBootstrap(t *testing.T) {
+	b := DefaultBootstrap()
+	for ip, want := range map[string]string{
+		"8.8.8.8":              "https://rdap.arin.net/registry/",
+		"183.95.1.1":           "https://rdap.apnic.net/",
+		"193.0.6.139":          "https://rdap.db.ripe.net/",
+		"200.160.2.3":          "https://rdap.lacnic.net/rdap/",
+		"41.1.1.1":             "https://rdap.afrinic.net/rdap/",
+		"::ffff:8.8.8.8":       "https://rdap.arin.net/registry/",
+		"2001:4860:4860::8888": "https://rdap.arin.net/registry/",
+		"2a00:1450::1":         "https://rdap.db.ripe.net/",
+	} {
+		if got, _ := b.Lookup(netip.MustParseAddr(ip)); got != want {
+			t.Fatalf("bootstrap of %s not match, got: %s", ip, got)
+		}
+	}
+	if _, ok := b.Lookup(netip.MustParseAddr("240.0.0.1")); ok {
+		t.Fatal("expected no server for reserved space")
+	}
+	if got := registryName("https://rdap.db.ripe.net/"); got != "ripencc" {
+		t.Fatalf("registry name not match, got: %s", got)
+	}
+}
+
+func TestLookupIP(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	n, err := c.LookupIP(ctx, "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Handle != "NET-8-8-8-0-2" || n.Name != "GOGL" || n.Country != "" || !slices.Equal(n.CIDRs, []netip.Prefix{netip.MustParsePrefix("8.8.8.0/24")}) {
+		t.Fatalf("arin network not match, got: %+v", n)
+	}
+	abuse := n.Abuse()
+	if abuse == nil || abuse.Handle != "ABUSE5250-ARIN" || abuse.Kind != "group" || abuse.Emails[0] != "network-abuse@google.com" || abuse.Phones[0] != "+1-650-253-0000" {
+		t.Fatalf("arin abuse not match, got: %+v", abuse)
+	}
+	if r := n.Registrant(); r == nil || r.Name != "Google LLC" || r.Country != "United States" {
+		t.Fatalf("arin registrant not match, got: %+v", r)
+	}
+
+	n, err = c.LookupIP(ctx, "193.0.6.139")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Country != "NL" || !slices.Equal(n.CIDRs, []netip.Prefix{netip.MustParsePrefix("193.0.0.0/21")}) || n.Remarks[0] != "Used for RIPE NCC infrastructure." {
+		t.Fatalf("ripe network not match, got: %+v", n)
+	}
+	if abuse := n.Abuse(); abuse.Address != "Stationsplein 11, Amsterdam, 1012 AB, NL" || abuse.Country != "NL" {
+		t.Fatalf("ripe abuse not match, got: %+v", abuse)
+	}
+
+	if _, err := c.LookupIP(ctx, "8.8.4.4"); !errors.Is(err, geoip.ErrNotFound) {
+		t.Fatalf("not found not match, got: %v", err)
+	}
+	_, err = c.LookupIP(ctx, "193.0.0.1")
+	var se *geoip.StatusError
+	if !errors.Is(err, geoip.ErrQuotaExceeded) || !errors.As(err, &se) || se.RetryAfter != 2*time.Minute {
+		t.Fatalf("quota not match, got: %v", err)
+	}
+	if _, err := c.LookupIP(ctx, "193.0.0.2"); !geoip.IsErrUnauthorized(err) {
+		t.Fatalf("forbidden not match, got: %v", err)
+	}
+	if _, err := c.LookupIP(ctx, "9.9.9.9"); !errors.Is(err, ErrNoServer) {
+		t.Fatalf("no server not match, got: %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := newTestClient(t)
+	// 作为兜底，第一个 provider 失败时使用 RDAP 的国家
+	db, err := geoip.LoadCSV(strings.NewReader(""), geoip.CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := geoip.New(geoip.English, geoip.WithHandlers(db, c))
+	info, err := e.Lookup(context.Background(), "8.8.8.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CountryCode != "US" || info.Country != "United States" || info.Org != "Google LLC" || info.Prefix != "8.8.8.0/24" {
+		t.Fatalf("info not match, got: %+v", info)
+	}
+	if info.Abuse == nil || info.Abuse.Email != "network-abuse@google.com" || info.Abuse.Network != "8.8.8.0/24" {
+		t.Fatalf("abuse not match, got: %+v", info.Abuse)
+	}
+}
+
+func TestRangeToPrefixes(t *testing.T) {
+	for _, tc := range []struct {
+		start, end string
+		want       []string
+	}{
+		{"10.0.0.0", "10.0.0.255", []string{"10.0.0.0/24"}},
+		{"10.0.0.1", "10.0.0.6", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"0.0.0.0", "255.255.255.255", []string{"0.0.0.0/0"}},
+		{"2001:db8::", "2001:db8::ffff", []string{"2001:db8::/112"}},
+	} {
+		var got []string
+		for _, p := range rangeToPrefixes(netip.MustParseAddr(tc.start), netip.MustParseAddr(tc.end)) {
+			got = append(got, p.String())
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("range %s-%s not match, got: %v", tc.start, tc.end, got)
+		}
+	}
+}